@@ -0,0 +1,177 @@
+// Package analysis looks for mistakes in recorded games: moves which were
+// objectively bad, whatever conventions the players were following.
+package analysis
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"fmt"
+)
+
+type MistakeKind int
+
+const (
+	// Discarding the last copy of a card which is still needed, when
+	// another card in the hand could have been discarded instead.
+	CriticalDiscard MistakeKind = iota
+	// Playing a card which hints had shown couldn't be played.
+	KnownMisplay
+	// Giving a hint which told the player nothing new.
+	UselessHint
+	// Discarding while holding every blue token, so no token was gained.
+	WastedBlueToken
+	// Not hinting the next player about a critical card on their chop, which
+	// they then discarded.
+	MissedSave
+)
+
+var kMistakeNames = map[MistakeKind]string{
+	CriticalDiscard: "critical discard",
+	KnownMisplay:    "known misplay",
+	UselessHint:     "useless hint",
+	WastedBlueToken: "wasted blue token",
+	MissedSave:      "missed save",
+}
+
+func (k MistakeKind) String() string {
+	return kMistakeNames[k]
+}
+
+// Annotation flags a mistake made on one turn.
+type Annotation struct {
+	// Index into GameRecord.Turns.
+	Turn    int
+	Player  yanhuo.PlayerIndex
+	Kind    MistakeKind
+	Message string
+}
+
+func (a Annotation) String() string {
+	return fmt.Sprintf("Turn %d (player %d): %s: %s", a.Turn+1, a.Player, a.Kind, a.Message)
+}
+
+// Returns an annotation for every mistake in the game, in turn order.
+func Analyze(record *yanhuo.GameRecord) ([]Annotation, error) {
+	tracker := yanhuo.NewBoardTracker()
+	if err := yanhuo.Replay(record, []yanhuo.Observer{tracker}); err != nil {
+		return nil, err
+	}
+
+	annotations := []Annotation{}
+	for i, turn := range tracker.Turns {
+		a := &analyzer{
+			turn:        i,
+			player:      turn.Player,
+			before:      tracker.Boards[i],
+			after:       tracker.Boards[i+1],
+			annotations: &annotations,
+		}
+		if i+1 < len(tracker.Turns) {
+			a.next = &tracker.Turns[i+1]
+		}
+
+		switch action := turn.Action; {
+		case action.GiveInformation != nil:
+			a.checkHint(action.GiveInformation)
+		case action.Discard != nil:
+			a.checkDiscard(action.Discard.Index)
+		case action.Play != nil:
+			a.checkPlay(action.Play.Index, turn.Successful)
+		}
+		a.checkSave()
+	}
+
+	return annotations, nil
+}
+
+// Checks a single turn.
+type analyzer struct {
+	turn   int
+	player yanhuo.PlayerIndex
+	// The board before and after the turn.
+	before yanhuo.Board
+	after  yanhuo.Board
+	// The following turn, if any.
+	next *yanhuo.BoardTurn
+
+	annotations *[]Annotation
+}
+
+func (a *analyzer) flag(kind MistakeKind, format string, args ...interface{}) {
+	*a.annotations = append(*a.annotations, Annotation{
+		Turn:    a.turn,
+		Player:  a.player,
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (a *analyzer) checkDiscard(i yanhuo.HandIndex) {
+	hand := a.before.Hands[a.player]
+	c := hand[i]
+
+	if a.before.BlueTokens == a.before.Rules.MaxBlueTokens {
+		a.flag(WastedBlueToken, "discarded %s with %d blue tokens", c, a.before.BlueTokens)
+	}
+
+	if !a.before.IsCritical(c) {
+		return
+	}
+	for j, other := range hand {
+		if j != int(i) && !a.before.IsCritical(other) {
+			a.flag(CriticalDiscard, "discarded %s, but card %d (%s) was safe to discard", c, j, other)
+			return
+		}
+	}
+}
+
+func (a *analyzer) checkPlay(i yanhuo.HandIndex, successful bool) {
+	if successful {
+		return
+	}
+	for _, possible := range a.before.Knowledge[a.player][i].PossibleCards() {
+		if a.before.IsPlayable(possible) {
+			return
+		}
+	}
+	c := a.before.Hands[a.player][i]
+	a.flag(KnownMisplay, "played %s, known to be %s, which couldn't be playable",
+		c, a.before.Knowledge[a.player][i])
+}
+
+func (a *analyzer) checkHint(hint *yanhuo.GiveInformationAction) {
+	before := a.before.Knowledge[hint.PlayerIndex]
+	after := a.after.Knowledge[hint.PlayerIndex]
+	for i := range before {
+		if !sameInformation(before[i], after[i]) {
+			return
+		}
+	}
+	a.flag(UselessHint, "told player %d nothing they didn't already know", hint.PlayerIndex)
+}
+
+// Whether two pieces of knowledge allow the same cards, ignoring whether a
+// hint has touched the card.
+func sameInformation(a yanhuo.CardKnowledge, b yanhuo.CardKnowledge) bool {
+	a.Touched, b.Touched = false, false
+	return a == b
+}
+
+func (a *analyzer) checkSave() {
+	if a.next == nil || a.before.BlueTokens == 0 {
+		return
+	}
+
+	next := a.next.Player
+	chop := a.before.Chop(next)
+	if chop < 0 {
+		return
+	}
+	c := a.before.Hands[next][chop]
+	if !a.before.IsCritical(c) || a.after.Knowledge[next][chop].Touched {
+		return
+	}
+	if d := a.next.Action.Discard; d != nil && int(d.Index) == chop {
+		a.flag(MissedSave, "player %d had %s on their chop, and discarded it next turn", next, c)
+	}
+}
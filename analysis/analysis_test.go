@@ -0,0 +1,136 @@
+package analysis
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"fmt"
+	"testing"
+)
+
+type move func(others map[yanhuo.PlayerIndex][]yanhuo.Card) yanhuo.Action
+
+// Makes the given moves, then discards its first card for the rest of the
+// game.
+type scripted struct {
+	moves []move
+}
+
+func (s *scripted) StartGame(me yanhuo.PlayerIndex, others map[yanhuo.PlayerIndex][]yanhuo.Card, numCards int, blue int, red int) {
+}
+
+func (s *scripted) Act(me yanhuo.PlayerIndex, others map[yanhuo.PlayerIndex][]yanhuo.Card, numCards int, blue int, red int) yanhuo.Action {
+	if len(s.moves) == 0 {
+		return discard(0)(others)
+	}
+	m := s.moves[0]
+	s.moves = s.moves[1:]
+	return m(others)
+}
+
+func (s *scripted) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+}
+
+func discard(i int) move {
+	return func(others map[yanhuo.PlayerIndex][]yanhuo.Card) yanhuo.Action {
+		return yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: yanhuo.HandIndex(i)}}
+	}
+}
+
+func play(i int) move {
+	return func(others map[yanhuo.PlayerIndex][]yanhuo.Card) yanhuo.Action {
+		return yanhuo.Action{Play: &yanhuo.PlayAction{Index: yanhuo.HandIndex(i)}}
+	}
+}
+
+func valueHint(p yanhuo.PlayerIndex, v yanhuo.Value) move {
+	return func(others map[yanhuo.PlayerIndex][]yanhuo.Card) yanhuo.Action {
+		return yanhuo.Action{GiveInformation: yanhuo.NewValueHint(p, others[p], v)}
+	}
+}
+
+func colorHint(p yanhuo.PlayerIndex, c yanhuo.Color) move {
+	return func(others map[yanhuo.PlayerIndex][]yanhuo.Card) yanhuo.Action {
+		return yanhuo.Action{GiveInformation: yanhuo.NewColorHint(p, others[p], c)}
+	}
+}
+
+// Returns a deck which deals the given hands to two players, followed by
+// the rest of a standard deck in order.
+func deckFor(hand0 []yanhuo.Card, hand1 []yanhuo.Card) []yanhuo.Card {
+	deck := []yanhuo.Card{}
+	used := map[yanhuo.Card]int{}
+	for i := range hand0 {
+		deck = append(deck, hand0[i], hand1[i])
+		used[hand0[i]]++
+		used[hand1[i]]++
+	}
+	for _, c := range yanhuo.NewDeck() {
+		if used[c] > 0 {
+			used[c]--
+			continue
+		}
+		deck = append(deck, c)
+	}
+	return deck
+}
+
+func card(color yanhuo.Color, v int) yanhuo.Card {
+	return yanhuo.Card{Color: color, Value: yanhuo.Value(v)}
+}
+
+func TestAnalyze(t *testing.T) {
+	deck := deckFor(
+		[]yanhuo.Card{card(yanhuo.RED, 5), card(yanhuo.WHITE, 1), card(yanhuo.BLUE, 2), card(yanhuo.BLUE, 3), card(yanhuo.GREEN, 4)},
+		[]yanhuo.Card{card(yanhuo.GREEN, 5), card(yanhuo.YELLOW, 1), card(yanhuo.YELLOW, 2), card(yanhuo.YELLOW, 3), card(yanhuo.YELLOW, 4)})
+
+	players := []yanhuo.PlayerStrategy{
+		&scripted{moves: []move{
+			discard(0),                  // R5 is critical, and we have 8 tokens
+			play(2),                     // a 2, with nothing on the piles
+			colorHint(1, yanhuo.YELLOW), // doesn't save G5
+			discard(3),                  // doesn't save G5 either
+		}},
+		&scripted{moves: []move{
+			valueHint(0, 2),
+			colorHint(0, yanhuo.WHITE),
+			colorHint(0, yanhuo.WHITE), // again
+			discard(0),                 // G5
+		}},
+	}
+
+	// find a seed where player 0 starts
+	var record *yanhuo.GameRecord
+	for seed := int64(1); record == nil; seed++ {
+		game, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Seed: seed, Deck: deck})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if game.Record().StartingPlayer == 0 {
+			game.Play()
+			record = game.Record()
+		}
+	}
+
+	annotations, err := Analyze(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := []string{}
+	for _, a := range annotations {
+		if a.Turn < 8 {
+			actual = append(actual, fmt.Sprintf("%d %d %s", a.Turn, a.Player, a.Kind))
+		}
+	}
+	expected := []string{
+		"0 0 wasted blue token",
+		"0 0 critical discard",
+		"2 0 known misplay",
+		"5 1 useless hint",
+		"6 0 missed save",
+		"7 1 critical discard",
+	}
+	if fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("Expected:\n%v\nGot:\n%v\nAll annotations:\n%v", expected, actual, annotations)
+	}
+}
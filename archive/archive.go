@@ -0,0 +1,223 @@
+// Package archive keeps the records of played games on disk, along with
+// metadata about each game (who played, the rules, the seed, how it ended
+// and when), so that games can be found again, e.g. every 3-player game
+// where alwaysplay scored less than 5, and their records fetched for replay
+// or analysis.
+//
+// An archive is a directory holding each record as JSON, in records/, and
+// an index of every game's metadata, one JSON object per line, in
+// index.jsonl. The index is only ever appended to, and is read into memory
+// when the archive is opened. Only one process should write to an archive
+// at a time.
+package archive
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// How a game ended.
+type EndReason string
+
+const (
+	Won        EndReason = "won"          // every pile was completed
+	StruckOut  EndReason = "struck out"   // the last red token was lost
+	OutOfCards EndReason = "out of cards" // everyone had a last turn after the deck ran out
+)
+
+// Game describes one archived game.
+type Game struct {
+	ID string
+
+	// Who played in each seat, e.g. the strategies' registry names.
+	Players    []string
+	NumPlayers int
+	Rules      yanhuo.Rules
+	Seed       int64
+
+	Score int
+	End   EndReason
+	Turns int
+
+	Started  time.Time
+	Finished time.Time
+}
+
+// Whether player played in at least one seat.
+func (g *Game) HasPlayer(player string) bool {
+	for _, p := range g.Players {
+		if p == player {
+			return true
+		}
+	}
+	return false
+}
+
+type Archive struct {
+	dir string
+
+	mu    sync.Mutex
+	games []*Game // in the order they were added
+	byID  map[string]*Game
+}
+
+const kIndexFile = "index.jsonl"
+
+// Opens the archive in dir, creating it if it doesn't exist.
+func Open(dir string) (*Archive, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "records"), 0755); err != nil {
+		return nil, err
+	}
+	a := &Archive{dir: dir, byID: make(map[string]*Game)}
+
+	f, err := os.Open(filepath.Join(dir, kIndexFile))
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var g Game
+		if err := json.Unmarshal(scanner.Bytes(), &g); err != nil {
+			return nil, fmt.Errorf("Couldn't read %s line %d: %v", kIndexFile, line, err)
+		}
+		a.games = append(a.games, &g)
+		a.byID[g.ID] = &g
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Archive) recordPath(id string) string {
+	return filepath.Join(a.dir, "records", id+".json")
+}
+
+// Works out how a completed game ended, by replaying it.
+func endReason(record *yanhuo.GameRecord) (EndReason, error) {
+	tracker := yanhuo.NewBoardTracker()
+	if err := yanhuo.Replay(record, []yanhuo.Observer{tracker}); err != nil {
+		return "", err
+	}
+	switch {
+	case tracker.Won:
+		return Won, nil
+	case tracker.Board().RedTokens == 0:
+		return StruckOut, nil
+	}
+	return OutOfCards, nil
+}
+
+// Adds a completed game to the archive. players says who played in each
+// seat. Returns an error if the record doesn't describe a legal, complete
+// game.
+func (a *Archive) Add(record *yanhuo.GameRecord, players []string, started time.Time, finished time.Time) (*Game, error) {
+	if len(players) != record.NumPlayers {
+		return nil, fmt.Errorf("Game was played by %d players, but %d were given", record.NumPlayers, len(players))
+	}
+	end, err := endReason(record)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g := &Game{
+		ID:         fmt.Sprintf("%s-%06d", finished.UTC().Format("20060102-150405"), len(a.games)+1),
+		Players:    append([]string{}, players...),
+		NumPlayers: record.NumPlayers,
+		Rules:      record.PlayedRules(),
+		Seed:       record.Seed,
+		Score:      record.Score,
+		End:        end,
+		Turns:      len(record.Turns),
+		Started:    started.UTC(),
+		Finished:   finished.UTC(),
+	}
+
+	// the record is written first, so that the index never refers to a
+	// missing record
+	if err := ioutil.WriteFile(a.recordPath(g.ID), data, 0644); err != nil {
+		return nil, err
+	}
+
+	line, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(a.dir, kIndexFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	a.games = append(a.games, g)
+	a.byID[g.ID] = g
+	return g, nil
+}
+
+// Returns the game with the given ID, or nil if there isn't one.
+func (a *Archive) Get(id string) *Game {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.byID[id]
+}
+
+// Returns the record of the game with the given ID.
+func (a *Archive) Record(id string) (*yanhuo.GameRecord, error) {
+	if a.Get(id) == nil {
+		return nil, fmt.Errorf("No game %q in the archive", id)
+	}
+	data, err := ioutil.ReadFile(a.recordPath(id))
+	if err != nil {
+		return nil, err
+	}
+	var record yanhuo.GameRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Returns the games matching q, oldest first.
+func (a *Archive) Query(q Query) []*Game {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	games := []*Game{}
+	for _, g := range a.games {
+		if q.Limit > 0 && len(games) == q.Limit {
+			break
+		}
+		if q.Matches(g) {
+			games = append(games, g)
+		}
+	}
+	return games
+}
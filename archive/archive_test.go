@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/registry"
+
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func play(t *testing.T, names []string, seed int64) *yanhuo.GameRecord {
+	players, err := registry.NewPlayers(names, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Seed: seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	return game.Record()
+}
+
+func ids(games []*Game) string {
+	s := []string{}
+	for _, g := range games {
+		s = append(s, fmt.Sprintf("%s:%d", strings.Join(g.Players, ","), g.Seed))
+	}
+	return strings.Join(s, " ")
+}
+
+func TestArchive(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, names := range [][]string{
+		{"alwaysplay", "alwaysplay", "alwaysplay"},
+		{"heuristic", "heuristic", "heuristic"},
+		{"heuristic", "alwaysplay"},
+		{"heuristic", "heuristic", "heuristic"},
+	} {
+		seed := int64(i%2 + 1)
+		finished := start.Add(time.Duration(i) * time.Hour)
+		if _, err := a.Add(play(t, names, seed), names, finished.Add(-time.Minute), finished); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := a.Add(play(t, []string{"heuristic", "heuristic"}, 1), []string{"heuristic"}, start, start); err == nil {
+		t.Errorf("Expected an error adding a game with the wrong number of players")
+	}
+
+	// everything is read back from disk
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	three, low, seed := 3, 4, int64(2)
+	for _, test := range []struct {
+		query    Query
+		expected string
+	}{
+		{Query{}, "alwaysplay,alwaysplay,alwaysplay:1 heuristic,heuristic,heuristic:2 heuristic,alwaysplay:1 heuristic,heuristic,heuristic:2"},
+		{Query{NumPlayers: 3, Player: "alwaysplay", MaxScore: &low}, "alwaysplay,alwaysplay,alwaysplay:1"},
+		{Query{NumPlayers: 3, MinScore: &three, End: OutOfCards}, "heuristic,heuristic,heuristic:2 heuristic,heuristic,heuristic:2"},
+		{Query{Seed: &seed, Limit: 1}, "heuristic,heuristic,heuristic:2"},
+		{Query{Player: "alwaysplay", End: StruckOut}, "alwaysplay,alwaysplay,alwaysplay:1 heuristic,alwaysplay:1"},
+		{Query{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour)}, "heuristic,heuristic,heuristic:2 heuristic,alwaysplay:1"},
+		{Query{Player: "random"}, ""},
+	} {
+		if got := ids(a.Query(test.query)); got != test.expected {
+			t.Errorf("Query %+v returned %q, expected %q", test.query, got, test.expected)
+		}
+	}
+
+	g := a.Query(Query{})[1]
+	if a.Get(g.ID) != g || a.Get("nonesuch") != nil {
+		t.Errorf("Couldn't look up game %s", g.ID)
+	}
+	record, err := a.Record(g.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Seed != g.Seed || record.Score != g.Score || len(record.Turns) != g.Turns {
+		t.Errorf("Record doesn't match %+v", g)
+	}
+	if err := yanhuo.Replay(record, nil); err != nil {
+		t.Errorf("Archived record doesn't replay: %v", err)
+	}
+	if _, err := a.Record("nonesuch"); err == nil {
+		t.Errorf("Expected an error fetching a missing record")
+	}
+}
@@ -0,0 +1,51 @@
+package archive
+
+import (
+	"time"
+)
+
+// Query selects archived games. Games must match every field which is set.
+type Query struct {
+	// If non-zero, only games with this many players.
+	NumPlayers int
+	// If set, only games where this player played in at least one seat.
+	Player string
+	// If set, only games dealt from this seed.
+	Seed *int64
+
+	// If set, only games scoring at least MinScore, or at most MaxScore.
+	MinScore *int
+	MaxScore *int
+
+	// If set, only games which ended this way.
+	End EndReason
+
+	// If set, only games which finished at or after Since, or before Until.
+	Since time.Time
+	Until time.Time
+
+	// If non-zero, at most this many games are returned.
+	Limit int
+}
+
+func (q Query) Matches(g *Game) bool {
+	switch {
+	case q.NumPlayers != 0 && g.NumPlayers != q.NumPlayers:
+		return false
+	case q.Player != "" && !g.HasPlayer(q.Player):
+		return false
+	case q.Seed != nil && g.Seed != *q.Seed:
+		return false
+	case q.MinScore != nil && g.Score < *q.MinScore:
+		return false
+	case q.MaxScore != nil && g.Score > *q.MaxScore:
+		return false
+	case q.End != "" && g.End != q.End:
+		return false
+	case !q.Since.IsZero() && g.Finished.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !g.Finished.Before(q.Until):
+		return false
+	}
+	return true
+}
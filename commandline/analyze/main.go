@@ -0,0 +1,63 @@
+// Command analyze reads a recorded game (a GameRecord as JSON) and prints
+// the mistakes made in it. It can also write an HTML report of the game
+// with the mistakes marked.
+//
+//	analyze [-html report.html] game.json
+package main
+
+import (
+	"github.com/mrjones/yanhuo/analysis"
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/report"
+
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+var htmlPath = flag.String("html", "", "If set, write an HTML report of the game to this file.")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-html report.html] game.json\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	data, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var record yanhuo.GameRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		log.Fatal(err)
+	}
+
+	annotations, err := analysis.Analyze(&record)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Score: %d, mistakes: %d\n", record.Score, len(annotations))
+	for _, a := range annotations {
+		fmt.Println(a)
+	}
+
+	if *htmlPath != "" {
+		f, err := os.Create(*htmlPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		r := &report.Report{Title: flag.Arg(0), Record: &record, Annotations: annotations}
+		if err := r.WriteHTML(f); err != nil {
+			log.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
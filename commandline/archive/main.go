@@ -0,0 +1,196 @@
+// Command archive plays games into a game archive, and finds games in it.
+//
+//	archive -dir games play -players heuristic,heuristic,heuristic [-games 100] [-seed 1]
+//	archive -dir games add -players a,b,c game.json...
+//	archive -dir games query [-players 3] [-player alwaysplay] [-max-score 4] ...
+//	archive -dir games get ID
+//
+// query lists the matching games, one per line, and get prints a game's
+// record as JSON, e.g. for analyze. For example, every 3-player game where
+// alwaysplay scored less than 5:
+//
+//	archive -dir games query -players 3 -player alwaysplay -max-score 4
+package main
+
+import (
+	"github.com/mrjones/yanhuo/archive"
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/registry"
+
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var dir = flag.String("dir", "", "The archive's directory.")
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s -dir dir play|add|query|get ...\n", os.Args[0])
+	os.Exit(2)
+}
+
+func main() {
+	flag.Parse()
+	if *dir == "" || flag.NArg() < 1 {
+		usage()
+	}
+
+	a, err := archive.Open(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "play":
+		play(a, args)
+	case "add":
+		add(a, args)
+	case "query":
+		query(a, args)
+	case "get":
+		get(a, args)
+	default:
+		usage()
+	}
+}
+
+func play(a *archive.Archive, args []string) {
+	fs := flag.NewFlagSet("play", flag.ExitOnError)
+	playerList := fs.String("players", "", "Comma-separated names of the strategies to play, one per seat.")
+	games := fs.Int("games", 1, "The number of games to play.")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "Seeds the first game; each game after uses the next seed.")
+	fs.Parse(args)
+	names := strings.Split(*playerList, ",")
+
+	for i := 0; i < *games; i++ {
+		gameSeed := *seed + int64(i)
+		players, err := registry.NewPlayers(names, gameSeed)
+		if err != nil {
+			log.Fatal(err)
+		}
+		game, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Seed: gameSeed})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		started := time.Now()
+		game.Play()
+		g, err := a.Add(game.Record(), names, started, time.Now())
+		if err != nil {
+			log.Fatal(err)
+		}
+		printGame(g)
+	}
+}
+
+func add(a *archive.Archive, args []string) {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	playerList := fs.String("players", "", "Comma-separated names of who played in each seat.")
+	fs.Parse(args)
+
+	for _, path := range fs.Args() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var record yanhuo.GameRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			log.Fatalf("Couldn't parse %s: %v", path, err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// when the game was played isn't recorded, so use when it was saved
+		g, err := a.Add(&record, strings.Split(*playerList, ","), info.ModTime(), info.ModTime())
+		if err != nil {
+			log.Fatalf("Couldn't add %s: %v", path, err)
+		}
+		printGame(g)
+	}
+}
+
+func query(a *archive.Archive, args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	numPlayers := fs.Int("players", 0, "Only games with this many players.")
+	player := fs.String("player", "", "Only games where this strategy played in at least one seat.")
+	seed := fs.Int64("seed", 0, "Only games dealt from this seed.")
+	minScore := fs.Int("min-score", 0, "Only games scoring at least this.")
+	maxScore := fs.Int("max-score", 0, "Only games scoring at most this.")
+	end := fs.String("end", "", "Only games which ended this way: \"won\", \"struck out\" or \"out of cards\".")
+	since := fs.String("since", "", "Only games which finished on or after this date (2006-01-02).")
+	until := fs.String("until", "", "Only games which finished before this date (2006-01-02).")
+	limit := fs.Int("limit", 0, "If set, list at most this many games.")
+	asJSON := fs.Bool("json", false, "List the games as JSON, one per line.")
+	fs.Parse(args)
+
+	q := archive.Query{
+		NumPlayers: *numPlayers,
+		Player:     *player,
+		End:        archive.EndReason(*end),
+		Since:      parseDate(*since),
+		Until:      parseDate(*until),
+		Limit:      *limit,
+	}
+	// zero is a meaningful seed or score, so these only apply if given
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "seed":
+			q.Seed = seed
+		case "min-score":
+			q.MinScore = minScore
+		case "max-score":
+			q.MaxScore = maxScore
+		}
+	})
+
+	for _, g := range a.Query(q) {
+		if *asJSON {
+			data, err := json.Marshal(g)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(data))
+		} else {
+			printGame(g)
+		}
+	}
+}
+
+func parseDate(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return t
+}
+
+func get(a *archive.Archive, args []string) {
+	if len(args) != 1 {
+		usage()
+	}
+	record, err := a.Record(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(data))
+}
+
+func printGame(g *archive.Game) {
+	fmt.Printf("%s  %-40s seed %-20d score %2d  %-12s %3d turns\n",
+		g.ID, strings.Join(g.Players, ","), g.Seed, g.Score, g.End, g.Turns)
+}
@@ -0,0 +1,101 @@
+// Command branch rewinds a recorded game to an earlier turn and plays it out
+// again from there, e.g. to see what would have happened if a player had
+// discarded instead of hinting. The input is a GameRecord or a GameTree as
+// JSON, and the branched game is added to the tree, which is written out as
+// JSON.
+//
+//	branch -turn 12 -players heuristic,heuristic,heuristic \
+//		[-action '{"Discard":{"Index":0}}'] [-name "discard instead"] \
+//		[-o tree.json] game.json
+//
+// Turns are counted from 0, so "-turn 12" replaces the 13th turn onwards.
+package main
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/registry"
+
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	turn       = flag.Int("turn", 0, "The number of recorded turns to keep before the branch.")
+	playerList = flag.String("players", "", "Comma-separated names of the strategies to finish the game with, one per seat.")
+	actionJSON = flag.String("action", "", "If set, an action (as JSON) to take in place of the recorded one at the branch.")
+	name       = flag.String("name", "", "A description of the branch.")
+	seed       = flag.Int64("seed", 1, "Seeds the strategies' random choices.")
+	outPath    = flag.String("o", "", "Where to write the game tree. Defaults to standard output.")
+)
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 || *playerList == "" {
+		fmt.Fprintf(os.Stderr, "Usage: %s -turn N -players a,b,... [-action json] [-name name] [-o tree.json] game.json\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	tree, err := readTree(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	players, err := registry.NewPlayers(strings.Split(*playerList, ","), *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	actions := []yanhuo.Action{}
+	if *actionJSON != "" {
+		var action yanhuo.Action
+		if err := json.Unmarshal([]byte(*actionJSON), &action); err != nil {
+			log.Fatalf("Couldn't parse action: %v", err)
+		}
+		actions = append(actions, action)
+	}
+
+	branch, err := tree.Branch(*name, *turn, actions, players, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "Original scored %d, branch scored %d\n", tree.Record.Score, branch.Record.Score)
+
+	data, err := json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *outPath == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*outPath, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Reads a GameTree, or a GameRecord to start a new tree from.
+func readTree(path string) (*yanhuo.GameTree, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree yanhuo.GameTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	if tree.Record != nil {
+		return &tree, nil
+	}
+
+	var record yanhuo.GameRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return yanhuo.NewGameTree(&record), nil
+}
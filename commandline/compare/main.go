@@ -0,0 +1,60 @@
+// Command compare plays two sets of strategies on the same deals and writes
+// a Markdown report of which scored better, for pasting into a code review.
+//
+//	compare -a heuristic,heuristic,heuristic -b safediscard,safediscard,safediscard \
+//		[-games 200] [-seed 1] [-divergent 10] [-o report.md]
+//
+// To compare a strategy before and after a change, register the old version
+// under another name.
+package main
+
+import (
+	"github.com/mrjones/yanhuo/compare"
+
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	a         = flag.String("a", "", "Comma-separated names of side A's strategies, one per seat.")
+	b         = flag.String("b", "", "Comma-separated names of side B's strategies, one per seat.")
+	games     = flag.Int("games", 200, "The number of deals to play.")
+	seed      = flag.Int64("seed", 1, "The seed of the first deal; each deal after uses the next seed.")
+	divergent = flag.Int("divergent", 10, "The number of deals where the scores differed most to list.")
+	outPath   = flag.String("o", "", "Where to write the report. Defaults to standard output.")
+)
+
+func main() {
+	flag.Parse()
+	if *a == "" || *b == "" || flag.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s -a a1,a2,... -b b1,b2,... [-games N] [-seed N] [-divergent N] [-o report.md]\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	result, err := compare.Run(compare.Config{
+		A:     strings.Split(*a, ","),
+		B:     strings.Split(*b, ","),
+		Games: *games,
+		Seed:  *seed,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := result.WriteMarkdown(out, *divergent); err != nil {
+		log.Fatal(err)
+	}
+}
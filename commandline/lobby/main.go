@@ -0,0 +1,48 @@
+// Command lobby runs a lobby server, where tables of built-in strategies,
+// remote bots and WebSocket players can be set up and played at. See
+// lobby.ServeHTTP for the API. People can play from a browser at the root
+// of the server.
+//
+//	lobby [-addr localhost:8080] [-records dir | -archive dir]
+package main
+
+import (
+	"github.com/mrjones/yanhuo/archive"
+	"github.com/mrjones/yanhuo/lobby"
+
+	"flag"
+	"log"
+	"net/http"
+	"os"
+)
+
+var (
+	addr     = flag.String("addr", "localhost:8080", "The address to serve on.")
+	records  = flag.String("records", "", "If set, a directory to save the records of completed games to.")
+	archived = flag.String("archive", "", "If set, a game archive (see the archive command) to add completed games to.")
+)
+
+func main() {
+	flag.Parse()
+
+	var store lobby.RecordStore
+	if *records != "" && *archived != "" {
+		log.Fatal("Only one of -records and -archive can be given")
+	}
+	if *archived != "" {
+		a, err := archive.Open(*archived)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = lobby.ArchiveStore{Archive: a}
+	}
+	if *records != "" {
+		if err := os.MkdirAll(*records, 0755); err != nil {
+			log.Fatal(err)
+		}
+		store = lobby.DirStore(*records)
+	}
+
+	log.Printf("Lobby serving on http://%s/", *addr)
+	log.Fatal(http.ListenAndServe(*addr, lobby.New(store)))
+}
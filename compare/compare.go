@@ -0,0 +1,183 @@
+// Package compare plays two sets of strategies on the same deals, to tell
+// whether a change to a strategy made it better. Every seed is played once
+// by each set of players, so both face the same deck and starting player,
+// and the scores are compared deal by deal with a paired t-test.
+package compare
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/registry"
+
+	"fmt"
+	"math"
+	"sort"
+)
+
+type Config struct {
+	// The registry names of the strategies in each seat, for each side.
+	// Both need the same number of seats.
+	A []string
+	B []string
+
+	// The number of deals to play, using seeds Seed, Seed+1, and so on.
+	Games int
+	Seed  int64
+
+	// The rules to play by. If nil, the standard rules are used.
+	Rules *yanhuo.Rules
+}
+
+// One deal, as played by each side.
+type Game struct {
+	Seed   int64
+	ScoreA int
+	ScoreB int
+}
+
+// How much better B did than A.
+func (g Game) Diff() int {
+	return g.ScoreB - g.ScoreA
+}
+
+type Result struct {
+	Config Config
+	Games  []Game // in the order played
+
+	MeanA float64
+	MeanB float64
+
+	// The mean and standard deviation of B's score minus A's, per deal.
+	MeanDiff   float64
+	StdDevDiff float64
+
+	// The paired t-test of whether the mean difference is non-zero: the t
+	// statistic, its degrees of freedom, and the two-sided p-value.
+	T  float64
+	DF int
+	P  float64
+
+	// The number of deals where B scored more than A, less, and the same.
+	Wins   int
+	Losses int
+	Ties   int
+}
+
+func play(names []string, seed int64, rules *yanhuo.Rules) (*yanhuo.GameRecord, error) {
+	players, err := registry.NewPlayers(names, seed)
+	if err != nil {
+		return nil, err
+	}
+	game, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Seed: seed, Rules: rules})
+	if err != nil {
+		return nil, err
+	}
+	game.Play()
+	return game.Record(), nil
+}
+
+// Plays every deal with both sides, and compares their scores.
+func Run(c Config) (*Result, error) {
+	if len(c.A) != len(c.B) {
+		return nil, fmt.Errorf("A has %d players but B has %d, so they can't play the same deals", len(c.A), len(c.B))
+	}
+	if c.Games < 1 {
+		return nil, fmt.Errorf("Need at least one game to compare, got %d", c.Games)
+	}
+
+	r := &Result{Config: c}
+	for i := 0; i < c.Games; i++ {
+		seed := c.Seed + int64(i)
+		a, err := play(c.A, seed, c.Rules)
+		if err != nil {
+			return nil, err
+		}
+		b, err := play(c.B, seed, c.Rules)
+		if err != nil {
+			return nil, err
+		}
+		if fmt.Sprint(a.Deck) != fmt.Sprint(b.Deck) || a.StartingPlayer != b.StartingPlayer {
+			return nil, fmt.Errorf("Seed %d dealt different games to A and B", seed)
+		}
+		r.Games = append(r.Games, Game{Seed: seed, ScoreA: a.Score, ScoreB: b.Score})
+	}
+
+	r.summarize()
+	return r, nil
+}
+
+func (r *Result) summarize() {
+	n := float64(len(r.Games))
+	for _, g := range r.Games {
+		r.MeanA += float64(g.ScoreA) / n
+		r.MeanB += float64(g.ScoreB) / n
+		r.MeanDiff += float64(g.Diff()) / n
+		switch {
+		case g.Diff() > 0:
+			r.Wins++
+		case g.Diff() < 0:
+			r.Losses++
+		default:
+			r.Ties++
+		}
+	}
+
+	r.DF = len(r.Games) - 1
+	if r.DF < 1 {
+		r.T, r.P = math.NaN(), math.NaN()
+		return
+	}
+
+	squares := 0.0
+	for _, g := range r.Games {
+		d := float64(g.Diff()) - r.MeanDiff
+		squares += d * d
+	}
+	r.StdDevDiff = math.Sqrt(squares / float64(r.DF))
+
+	if r.StdDevDiff == 0 {
+		// every deal differed by the same amount
+		if r.MeanDiff == 0 {
+			r.T, r.P = 0, 1
+		} else {
+			r.T, r.P = math.Copysign(math.Inf(1), r.MeanDiff), 0
+		}
+		return
+	}
+	r.T = r.MeanDiff / r.StdError()
+	r.P = studentTwoSidedP(r.T, r.DF)
+}
+
+// The standard error of the mean difference.
+func (r *Result) StdError() float64 {
+	return r.StdDevDiff / math.Sqrt(float64(len(r.Games)))
+}
+
+// Whether the difference is significant at the given level, e.g. 0.05.
+func (r *Result) Significant(level float64) bool {
+	return r.P < level
+}
+
+// Returns the n deals where the scores differed most, biggest difference
+// first. Deals where the scores were the same are left out.
+func (r *Result) Divergent(n int) []Game {
+	games := []Game{}
+	for _, g := range r.Games {
+		if g.Diff() != 0 {
+			games = append(games, g)
+		}
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		return abs(games[i].Diff()) > abs(games[j].Diff())
+	})
+	if len(games) > n {
+		games = games[:n]
+	}
+	return games
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,103 @@
+package compare
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStudentTwoSidedP(t *testing.T) {
+	for _, test := range []struct {
+		t        float64
+		df       int
+		expected float64
+	}{
+		{0, 5, 1},
+		{1, 1, 0.5},
+		{2, 10, 0.07339},
+		{-2, 10, 0.07339},
+		{2.228, 10, 0.05},
+		{1.96, 100000, 0.05},
+		{10, 30, 4.5753e-11},
+	} {
+		p := studentTwoSidedP(test.t, test.df)
+		if math.Abs(p-test.expected) > 0.001*test.expected+1e-12 {
+			t.Errorf("p for t = %g with %d degrees of freedom was %g, expected %g", test.t, test.df, p, test.expected)
+		}
+	}
+}
+
+func TestSameStrategies(t *testing.T) {
+	players := []string{"heuristic", "heuristic"}
+	r, err := Run(Config{A: players, B: players, Games: 5, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.MeanDiff != 0 || r.Ties != 5 || r.P != 1 || len(r.Divergent(3)) != 0 {
+		t.Errorf("The same strategies should score the same on every deal: %+v", r)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	r, err := Run(Config{
+		A:     []string{"alwaysplay", "alwaysplay", "alwaysplay"},
+		B:     []string{"heuristic", "heuristic", "heuristic"},
+		Games: 20,
+		Seed:  100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Games) != 20 || r.Games[0].Seed != 100 || r.Games[19].Seed != 119 {
+		t.Fatalf("Unexpected games: %+v", r.Games)
+	}
+	if r.MeanB <= r.MeanA || math.Abs(r.MeanDiff-(r.MeanB-r.MeanA)) > 1e-9 {
+		t.Errorf("heuristic should beat alwaysplay: %.2f vs %.2f, difference %.2f", r.MeanB, r.MeanA, r.MeanDiff)
+	}
+	if !r.Significant(0.001) || r.DF != 19 || r.T <= 0 {
+		t.Errorf("Expected a significant difference: t = %g, df = %d, p = %g", r.T, r.DF, r.P)
+	}
+	if r.Wins+r.Losses+r.Ties != 20 {
+		t.Errorf("Wins, losses and ties should cover every deal: %d, %d, %d", r.Wins, r.Losses, r.Ties)
+	}
+
+	divergent := r.Divergent(3)
+	if len(divergent) != 3 {
+		t.Fatalf("Expected 3 divergent deals, got %d", len(divergent))
+	}
+	for _, g := range r.Games {
+		if abs(g.Diff()) > abs(divergent[0].Diff()) {
+			t.Errorf("Seed %d diverged more than seed %d", g.Seed, divergent[0].Seed)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	report := buf.String()
+	for _, expected := range []string{
+		"## alwaysplay, alwaysplay, alwaysplay vs heuristic, heuristic, heuristic",
+		"20 deals (seeds 100 to 119), 3 players",
+		"B is significantly better",
+		"### Most divergent deals",
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Report should contain %q:\n%s", expected, report)
+		}
+	}
+}
+
+func TestBadConfig(t *testing.T) {
+	for _, c := range []Config{
+		{A: []string{"heuristic", "heuristic"}, B: []string{"heuristic"}, Games: 1},
+		{A: []string{"heuristic", "heuristic"}, B: []string{"heuristic", "heuristic"}},
+		{A: []string{"heuristic", "nonesuch"}, B: []string{"heuristic", "heuristic"}, Games: 1},
+	} {
+		if _, err := Run(c); err == nil {
+			t.Errorf("Expected an error running %+v", c)
+		}
+	}
+}
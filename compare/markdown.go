@@ -0,0 +1,81 @@
+package compare
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+)
+
+// The significance level the report judges the difference by.
+const kSignificance = 0.05
+
+var kMarkdown = template.Must(template.New("report").Funcs(template.FuncMap{
+	"signed": func(x float64) string { return fmt.Sprintf("%+.2f", x) },
+}).Parse(`## {{.A}} vs {{.B}}
+
+{{.Games}} deals (seeds {{.FirstSeed}} to {{.LastSeed}}), {{.Players}} players, {{.Rules}}. Both sides played every deal.
+
+| | Players | Mean score |
+|---|---|---|
+| A | {{.A}} | {{printf "%.2f" .Result.MeanA}} |
+| B | {{.B}} | {{printf "%.2f" .Result.MeanB}} |
+
+**B − A: {{signed .Result.MeanDiff}}** per deal (standard error {{printf "%.2f" .StdError}}).
+B scored more on {{.Result.Wins}} deals, less on {{.Result.Losses}}, and the same on {{.Result.Ties}}.
+
+Paired t-test: t = {{printf "%.3f" .Result.T}}, df = {{.Result.DF}}, p = {{printf "%.4g" .Result.P}}. {{.Verdict}}
+{{if .Divergent}}
+### Most divergent deals
+
+| Seed | A | B | B − A |
+|---|---|---|---|
+{{range .Divergent}}| {{.Seed}} | {{.ScoreA}} | {{.ScoreB}} | {{printf "%+d" .Diff}} |
+{{end}}{{end}}`))
+
+type markdownPage struct {
+	Result    *Result
+	A, B      string
+	Games     int
+	FirstSeed int64
+	LastSeed  int64
+	Players   int
+	Rules     string
+	StdError  float64
+	Verdict   string
+	Divergent []Game
+}
+
+// Writes the comparison as Markdown, e.g. for a code review, listing up to
+// divergent of the deals where the sides' scores differed most.
+func (r *Result) WriteMarkdown(w io.Writer, divergent int) error {
+	page := markdownPage{
+		Result:    r,
+		A:         strings.Join(r.Config.A, ", "),
+		B:         strings.Join(r.Config.B, ", "),
+		Games:     len(r.Games),
+		FirstSeed: r.Config.Seed,
+		LastSeed:  r.Config.Seed + int64(len(r.Games)) - 1,
+		Players:   len(r.Config.A),
+		Rules:     "standard rules",
+		StdError:  r.StdError(),
+		Divergent: r.Divergent(divergent),
+	}
+	if r.Config.Rules != nil {
+		page.Rules = "custom rules"
+	}
+
+	switch {
+	case math.IsNaN(r.P):
+		page.Verdict = "Too few deals to test."
+	case r.Significant(kSignificance) && r.MeanDiff > 0:
+		page.Verdict = fmt.Sprintf("B is significantly better (at the %g level).", kSignificance)
+	case r.Significant(kSignificance):
+		page.Verdict = fmt.Sprintf("B is significantly worse (at the %g level).", kSignificance)
+	default:
+		page.Verdict = fmt.Sprintf("The difference isn't significant (at the %g level).", kSignificance)
+	}
+
+	return kMarkdown.Execute(w, page)
+}
@@ -0,0 +1,86 @@
+package compare
+
+import (
+	"math"
+)
+
+// Returns the probability of a t statistic at least as far from zero as t,
+// under Student's t distribution with df degrees of freedom.
+func studentTwoSidedP(t float64, df int) float64 {
+	v := float64(df)
+	return regularizedBeta(v/(v+t*t), v/2, 0.5)
+}
+
+// Returns the regularized incomplete beta function I_x(a, b), using its
+// continued fraction (as in Numerical Recipes, section 6.4).
+func regularizedBeta(x float64, a float64, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lgab, _ := math.Lgamma(a + b)
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+
+	// the continued fraction converges quickly on this side; use the
+	// symmetry I_x(a, b) = 1 - I_1-x(b, a) on the other
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(x, a, b) / a
+	}
+	return 1 - front*betaFraction(1-x, b, a)/b
+}
+
+// Evaluates the continued fraction for the incomplete beta function with
+// the modified Lentz method.
+func betaFraction(x float64, a float64, b float64) float64 {
+	const (
+		kMaxIterations = 300
+		kEpsilon       = 1e-15
+		kTiny          = 1e-300
+	)
+
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < kTiny {
+		d = kTiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1.0; m <= kMaxIterations; m++ {
+		// the even step
+		num := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 + num*d
+		if math.Abs(d) < kTiny {
+			d = kTiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < kTiny {
+			c = kTiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// the odd step
+		num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 + num*d
+		if math.Abs(d) < kTiny {
+			d = kTiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < kTiny {
+			c = kTiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+
+		if math.Abs(delta-1) < kEpsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,216 @@
+package yanhuo
+
+// Board is a snapshot of everything on the table at one point in a game.
+type Board struct {
+	// Indexed by PlayerIndex
+	Hands [][]Card
+	// What each player has been told about their own hand, indexed the same
+	// way as Hands.
+	Knowledge []HandKnowledge
+	// The turn on which each card was drawn, indexed the same way as Hands.
+	// Cards in the initial deal have negative turns, in the order dealt.
+	Drawn [][]int
+
+	Piles map[Color]int
+	// Every card discarded or misplayed, in order.
+	Discards []Card
+
+	BlueTokens int
+	RedTokens  int
+	DeckSize   int
+
+	Rules Rules
+}
+
+// Returns a deep copy of the board.
+func (b Board) Copy() Board {
+	c := b
+	c.Hands = make([][]Card, len(b.Hands))
+	c.Knowledge = make([]HandKnowledge, len(b.Knowledge))
+	c.Drawn = make([][]int, len(b.Drawn))
+	for p := range b.Hands {
+		c.Hands[p] = append([]Card{}, b.Hands[p]...)
+		c.Knowledge[p] = append(HandKnowledge{}, b.Knowledge[p]...)
+		c.Drawn[p] = append([]int{}, b.Drawn[p]...)
+	}
+	c.Piles = copyPiles(b.Piles)
+	c.Discards = append([]Card{}, b.Discards...)
+	c.Rules = b.Rules.copy()
+	return c
+}
+
+func (b Board) discardCounts() map[Card]int {
+	counts := map[Card]int{}
+	for _, c := range b.Discards {
+		counts[c]++
+	}
+	return counts
+}
+
+// Whether c could be played now.
+func (b Board) IsPlayable(c Card) bool {
+	return int(c.Value) == b.Piles[c.Color]+1
+}
+
+// Whether c has already been played, or can never be played because every
+// copy of a lower card in its color has been discarded.
+func (b Board) IsDead(c Card) bool {
+	return isDead(b.Piles, b.discardCounts(), c)
+}
+
+// Whether c is still needed and is the last copy not yet discarded.
+func (b Board) IsCritical(c Card) bool {
+	return isCritical(b.Piles, b.discardCounts(), c)
+}
+
+// Returns the highest score still possible, given the cards discarded.
+func (b Board) MaxScore() int {
+	return maxScore(b.discardCounts())
+}
+
+// Returns the game's pace (see View.Pace).
+func (b Board) Pace() int {
+	return pace(b.Piles, b.DeckSize, len(b.Hands), b.MaxScore())
+}
+
+// Returns the index of player p's chop: their oldest card which no hint has
+// touched, or -1 if every card has been touched.
+func (b Board) Chop(p PlayerIndex) int {
+	chop := -1
+	for i, k := range b.Knowledge[p] {
+		if !k.Touched && (chop == -1 || b.Drawn[p][i] < b.Drawn[p][chop]) {
+			chop = i
+		}
+	}
+	return chop
+}
+
+// One turn, as seen by a BoardTracker.
+type BoardTurn struct {
+	Player PlayerIndex
+	Action Action
+
+	// The card played or discarded, if any, and whether a play succeeded.
+	Card       *Card
+	Successful bool
+	// The card drawn to replace it, if any.
+	Drew *Card
+}
+
+// BoardTracker is an Observer which keeps track of the whole board,
+// including what each player knows about their hand, and remembers how it
+// looked before every turn.
+type BoardTracker struct {
+	// Boards[0] is the board after the deal, and Boards[i] is the board
+	// after i turns.
+	Boards []Board
+	Turns  []BoardTurn
+	Won    bool
+
+	board   Board
+	rules   Rules
+	turn    *BoardTurn
+	leaving HandIndex // the index of the card played or discarded
+}
+
+func NewBoardTracker() *BoardTracker {
+	return &BoardTracker{rules: StandardRules()}
+}
+
+// Picks up the rules of the game. Without a View, the tracker assumes the
+// standard rules.
+func (t *BoardTracker) ObserveView(v *View) {
+	t.rules = v.Rules()
+}
+
+// Returns the board as it is now.
+func (t *BoardTracker) Board() Board {
+	return t.board.Copy()
+}
+
+func (t *BoardTracker) GameStart(cards [][]Card) {
+	t.board = Board{
+		Hands:      make([][]Card, len(cards)),
+		Knowledge:  make([]HandKnowledge, len(cards)),
+		Drawn:      make([][]int, len(cards)),
+		Piles:      make(map[Color]int),
+		Discards:   []Card{},
+		BlueTokens: t.rules.MaxBlueTokens,
+		RedTokens:  t.rules.RedTokens,
+		Rules:      t.rules,
+		DeckSize:   len(NewDeck()),
+	}
+	for _, c := range ALL_COLORS {
+		t.board.Piles[c] = 0
+	}
+	for p, playerCards := range cards {
+		t.board.Hands[p] = append([]Card{}, playerCards...)
+		t.board.Knowledge[p] = NewHandKnowledge(len(playerCards))
+		t.board.Drawn[p] = make([]int, len(playerCards))
+		for i := range playerCards {
+			// cards are dealt one to each player in turn
+			t.board.Drawn[p][i] = (i-len(playerCards))*len(cards) + p
+		}
+		t.board.DeckSize -= len(playerCards)
+	}
+
+	t.Boards = []Board{t.board.Copy()}
+	t.Turns = []BoardTurn{}
+	t.Won = false
+}
+
+func (t *BoardTracker) ObserveAction(p PlayerIndex, a Action) {
+	t.turn = &BoardTurn{Player: p, Action: a}
+	if a.GiveInformation != nil {
+		t.board.Knowledge[a.GiveInformation.PlayerIndex].ApplyHintWithRules(a.GiveInformation, t.rules)
+	}
+}
+
+func (t *BoardTracker) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	t.turn.Card = &c
+	t.leaving = i
+	t.board.Discards = append(t.board.Discards, c)
+}
+
+func (t *BoardTracker) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	t.turn.Drew = &c
+	t.board.Hands[p][i] = c
+	t.board.Drawn[p][i] = len(t.Turns)
+	t.board.DeckSize--
+}
+
+func (t *BoardTracker) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	t.turn.Card = &c
+	t.turn.Successful = successful
+	if a := t.turn.Action; a.Play != nil {
+		t.leaving = a.Play.Index
+	}
+	if !successful {
+		t.board.Discards = append(t.board.Discards, c)
+	}
+}
+
+func (t *BoardTracker) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	if t.turn.Card != nil {
+		p := t.turn.Player
+		drew := t.turn.Drew != nil
+		if !drew {
+			hand, drawn := t.board.Hands[p], t.board.Drawn[p]
+			t.board.Hands[p] = append(hand[:t.leaving], hand[t.leaving+1:]...)
+			t.board.Drawn[p] = append(drawn[:t.leaving], drawn[t.leaving+1:]...)
+		}
+		t.board.Knowledge[p] = t.board.Knowledge[p].Replace(t.leaving, drew)
+	}
+
+	t.board.Piles = copyPiles(piles)
+	t.board.BlueTokens = blueTokens
+	t.board.RedTokens = redTokens
+
+	t.Turns = append(t.Turns, *t.turn)
+	t.Boards = append(t.Boards, t.board.Copy())
+	t.turn = nil
+}
+
+func (t *BoardTracker) GameComplete(won bool, piles map[Color]int) {
+	t.Won = won
+}
@@ -0,0 +1,109 @@
+package yanhuo
+
+import (
+	"fmt"
+)
+
+// Checkpoint holds everything needed to resume a game which was interrupted,
+// e.g. because a remote player went away. It can be saved as JSON.
+//
+// The game is reconstructed by playing back Record, so the rest is a
+// snapshot of the table for reference, which Resume checks against.
+type Checkpoint struct {
+	// The game so far: the deck order, rules, and every completed turn.
+	Record *GameRecord
+
+	Turn          int // the number of turns taken
+	CurrentPlayer PlayerIndex
+	Hands         [][]Card
+	Piles         map[Color]int
+	Discards      []Card
+	DeckSize      int
+	BlueTokens    int
+	RedTokens     int
+}
+
+// Returns a checkpoint of the game as of the last completed turn. It can be
+// taken between turns, or after Play panics part way through a turn (e.g.
+// because a player failed), in which case the unfinished turn will be taken
+// again when the game is resumed. Games which are over can't be checkpointed.
+func (game *gameState) Checkpoint() (*Checkpoint, error) {
+	record := game.Record()
+	record.Turns = record.Turns[:game.completedTurns]
+	record.Won = false
+	record.Score = 0
+	return NewCheckpoint(record)
+}
+
+// Returns a checkpoint of the game described by record, after all of its
+// turns.
+func NewCheckpoint(record *GameRecord) (*Checkpoint, error) {
+	next := 0
+	players := make([]PlayerStrategy, record.NumPlayers)
+	for i := range players {
+		players[i] = &scriptedStrategy{next: &next}
+	}
+
+	game, err := Rewind(record, len(record.Turns), players, nil)
+	if err != nil {
+		return nil, err
+	}
+	return game.snapshot(), nil
+}
+
+func (game *gameState) snapshot() *Checkpoint {
+	c := &Checkpoint{
+		Record:        game.Record(),
+		Turn:          game.completedTurns,
+		CurrentPlayer: game.currentPlayer,
+		Hands:         make([][]Card, len(game.playerStates)),
+		Piles:         copyPiles(game.pileHeights),
+		Discards:      append([]Card{}, game.discards...),
+		DeckSize:      len(game.drawPile),
+		BlueTokens:    game.blueTokens,
+		RedTokens:     game.redTokens,
+	}
+	for i, player := range game.playerStates {
+		c.Hands[i] = append([]Card{}, player.cards...)
+	}
+	return c
+}
+
+// Restores a checkpointed game, ready to continue with the given players:
+// calling Play on the result finishes the game. As with Rewind, players are
+// started as usual and then shown every turn taken so far, so that they can
+// rebuild what they knew. Returns an error if the checkpoint's snapshot of
+// the table doesn't match its record.
+func Resume(c *Checkpoint, players []PlayerStrategy, observers []Observer) (*gameState, error) {
+	if c.Record == nil {
+		return nil, fmt.Errorf("Checkpoint has no record")
+	}
+	if c.Turn != len(c.Record.Turns) {
+		return nil, fmt.Errorf("Checkpoint is at turn %d, but has a record of %d turns", c.Turn, len(c.Record.Turns))
+	}
+
+	game, err := Rewind(c.Record, c.Turn, players, observers)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := game.snapshot()
+	for _, field := range []struct {
+		name            string
+		saved, replayed interface{}
+	}{
+		{"current player", c.CurrentPlayer, restored.CurrentPlayer},
+		{"hands", c.Hands, restored.Hands},
+		{"piles", c.Piles, restored.Piles},
+		{"discards", c.Discards, restored.Discards},
+		{"deck size", c.DeckSize, restored.DeckSize},
+		{"blue tokens", c.BlueTokens, restored.BlueTokens},
+		{"red tokens", c.RedTokens, restored.RedTokens},
+	} {
+		if fmt.Sprint(field.saved) != fmt.Sprint(field.replayed) {
+			return nil, fmt.Errorf("Checkpoint has %s %v, but its record gives %v",
+				field.name, field.saved, field.replayed)
+		}
+	}
+	return game, nil
+}
@@ -0,0 +1,89 @@
+package yanhuo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// A firstCardHinter which goes away after a number of turns, like a remote
+// player whose connection drops.
+type unreliablePlayer struct {
+	firstCardHinter
+	turnsLeft int
+}
+
+func (p *unreliablePlayer) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	if p.turnsLeft == 0 {
+		panic("connection lost")
+	}
+	p.turnsLeft--
+	return p.firstCardHinter.Act(me, others, numCards, blue, red)
+}
+
+func TestCheckpointAndResume(t *testing.T) {
+	game, err := InitializeGameWithOptions(hinters(3), nil, GameOptions{Seed: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	uninterrupted := game.Record()
+
+	players := hinters(3)
+	players[1] = &unreliablePlayer{turnsLeft: 4}
+	game, err = InitializeGameWithOptions(players, nil, GameOptions{Seed: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("Expected the unreliable player to interrupt the game")
+			}
+		}()
+		game.Play()
+	}()
+
+	checkpoint, err := game.Checkpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.Turn < 10 || checkpoint.CurrentPlayer != 1 || len(checkpoint.Hands) != 3 {
+		t.Errorf("Unexpected checkpoint: turn %d, player %d's turn", checkpoint.Turn, checkpoint.CurrentPlayer)
+	}
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed, err := Resume(&saved, hinters(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.Play()
+	record := resumed.Record()
+
+	if len(record.Turns) != len(uninterrupted.Turns) || record.Score != uninterrupted.Score {
+		t.Fatalf("Resumed game took %d turns and scored %d, uninterrupted took %d and scored %d",
+			len(record.Turns), record.Score, len(uninterrupted.Turns), uninterrupted.Score)
+	}
+	for i := range record.Turns {
+		if a, b := uninterrupted.Turns[i].Action.DebugString(), record.Turns[i].Action.DebugString(); a != b {
+			t.Errorf("Turn %d differs: %s vs %s", i, a, b)
+		}
+	}
+
+	tampered := saved
+	tampered.BlueTokens++
+	if _, err := Resume(&tampered, hinters(3), nil); err == nil {
+		t.Errorf("Expected an error resuming a checkpoint which doesn't match its record")
+	}
+
+	if _, err := resumed.Checkpoint(); err == nil {
+		t.Errorf("Expected an error checkpointing a finished game")
+	}
+}
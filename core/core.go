@@ -54,6 +54,57 @@ type PlayerStrategy interface {
 	ObserveAction(actor PlayerIndex, action Action)
 }
 
+// PlayerStrategies may optionally implement TableObserver to be shown the
+// cards revealed at the table, as a human player would see them: every card
+// that is played or discarded (including their own), and every card drawn by
+// the other players. These calls happen while the action is being resolved,
+// before ObserveAction.
+type TableObserver interface {
+	ObserveDiscard(p PlayerIndex, c Card, i HandIndex)
+	ObserveDraw(p PlayerIndex, c Card, i HandIndex)
+	ObservePlay(p PlayerIndex, c Card, successful bool)
+}
+
+// A PlayerStrategy which also implements CheatingStrategy is shown its own
+// cards before each of its turns. This is only for analysis: such strategies
+// can only play in games started with GameOptions.AllowCheating.
+type CheatingStrategy interface {
+	PlayerStrategy
+
+	ObserveOwnCards(cards []Card)
+}
+
+// PlayerStrategies and Observers may optionally implement ViewObserver to
+// be given a View of the game before it starts. The View stays up to date
+// as the game is played.
+type ViewObserver interface {
+	ObserveView(v *View)
+}
+
+// PlayerStrategies which can only play by some rules implement RulesChecker,
+// e.g. to refuse variant suits. Games can't be started with a strategy
+// which refuses their rules.
+type RulesChecker interface {
+	// Returns an error if the strategy can't play by rules.
+	CheckRules(rules Rules) error
+}
+
+// PlayerStrategies may optionally implement Rejoiner to take over a seat in
+// a game which is already under way (see Rewind). While the turns before
+// the strategy joined are played back, Rejoin is called in place of Act for
+// its own turns, with the action that was taken. The strategy should update
+// itself as if it had chosen that action. Everything else happens as in any
+// other game.
+type Rejoiner interface {
+	Rejoin(
+		myPlayerIndex PlayerIndex,
+		otherPlayersCards map[PlayerIndex][]Card,
+		myNumCards int,
+		blueTokens int,
+		redTokens int,
+		action Action)
+}
+
 type Action struct {
 	// Exactly one one must be non-null
 	GiveInformation *GiveInformationAction `json:",omitempty"`
@@ -112,8 +163,6 @@ type Observer interface {
 //
 
 const (
-	kMaxBlueTokens = 8
-
 	kKeepGoing = true
 	kStop      = false
 )
@@ -203,14 +252,29 @@ type playerState struct {
 type gameState struct {
 	drawPile     []Card
 	pileHeights  map[Color]int
+	discards     []Card // discarded and misplayed cards, in order
+	knowledge    []HandKnowledge // what each player has been told about their hand
 	playerStates []*playerState
 	observers    []Observer
+	record       *GameRecord
+	cheating     bool
+	rules        Rules
+
+	// turns to play back before the players take over (see Rewind)
+	history []TurnRecord
 
 	redTokens  int // bad plays
 	blueTokens int // available information
 
 	currentPlayer PlayerIndex
 
+	// number of turns taken since the draw pile ran out
+	finalTurns int
+
+	// number of turns which have been fully resolved
+	completedTurns int
+
+	started  bool
 	finished bool
 	won      bool
 }
@@ -221,13 +285,19 @@ func (game *gameState) drawReplacement(player *playerState, card HandIndex) {
 		drawn := game.drawPile[0]
 		player.cards[card] = drawn
 		game.drawPile = game.drawPile[1:]
+		game.knowledge[game.currentPlayer] = game.knowledge[game.currentPlayer].Replace(card, true)
 		for _, o := range game.observers {
 			o.ObserveDraw(game.currentPlayer, drawn, card)
 		}
+		for i, p := range game.playerStates {
+			if t, ok := p.strategy.(TableObserver); ok && PlayerIndex(i) != game.currentPlayer {
+				t.ObserveDraw(game.currentPlayer, drawn, card)
+			}
+		}
 	} else {
 		// nothing to draw, remove this card
-		player.cards[card] = player.cards[len(player.cards)-1]
-		player.cards = player.cards[1:]
+		player.cards = append(player.cards[:card], player.cards[card+1:]...)
+		game.knowledge[game.currentPlayer] = game.knowledge[game.currentPlayer].Replace(card, false)
 	}
 }
 
@@ -240,6 +310,10 @@ func (game *gameState) handleGiveInformationAction(action *GiveInformationAction
 		panic("Invalid action:" + action.invalidReason())
 	}
 
+	if action.Color != nil && !game.rules.CanName(action.Color.Color) {
+		panic("Invalid action: GiveInformationAction names a color which can't be hinted")
+	}
+
 	recipient := game.playerStates[action.PlayerIndex]
 	for candidateCardPos, candidateCard := range recipient.cards {
 		givingInformationAboutThisCard := false
@@ -249,26 +323,20 @@ func (game *gameState) handleGiveInformationAction(action *GiveInformationAction
 			}
 		}
 
-		if givingInformationAboutThisCard {
-			// check that the information matches this card
-			if action.Color != nil && candidateCard.Color != action.Color.Color {
-				panic("Invalid action: GiveInformationAction color does not match actual card color")
-			}
-			if action.Value != nil && candidateCard.Value != action.Value.Value {
-				panic("Invalid action: GiveInformationAction value does not match actual card value")
-			}
-		} else {
-			// check that the information does NOT apply to this card
-			if action.Color != nil && candidateCard.Color == action.Color.Color {
-				panic("Invalid action: GiveInformationAction color matches un-referenced card")
-			}
-			if action.Value != nil && candidateCard.Value == action.Value.Value {
-				panic("Invalid action: GiveInformationAction value matches un-referenced card")
-			}
-
+		touched := game.rules.Touches(action, candidateCard)
+		if givingInformationAboutThisCard && !touched {
+			panic("Invalid action: GiveInformationAction refers to a card the information doesn't apply to")
+		}
+		if !givingInformationAboutThisCard && touched {
+			panic("Invalid action: GiveInformationAction information applies to un-referenced card")
 		}
 	}
 
+	knowledge := game.knowledge[action.PlayerIndex]
+	effect := MeasureHintWithRules(knowledge, action, game.rules)
+	knowledge.ApplyHintWithRules(action, game.rules)
+	game.record.Turns[len(game.record.Turns)-1].Hint = &effect
+
 	game.blueTokens--
 	return kKeepGoing
 }
@@ -280,13 +348,19 @@ func (game *gameState) handleDiscardAction(player *playerState, action *DiscardA
 	}
 
 	card := player.cards[action.Index]
+	game.discards = append(game.discards, card)
 	for _, o := range game.observers {
 		o.ObserveDiscard(game.currentPlayer, card, action.Index)
 	}
+	for _, p := range game.playerStates {
+		if t, ok := p.strategy.(TableObserver); ok {
+			t.ObserveDiscard(game.currentPlayer, card, action.Index)
+		}
+	}
 
 	game.drawReplacement(player, action.Index)
 
-	if game.blueTokens < kMaxBlueTokens {
+	if game.blueTokens < game.rules.MaxBlueTokens {
 		game.blueTokens++
 	}
 
@@ -301,12 +375,23 @@ func (game *gameState) handlePlayAction(player *playerState, action *PlayAction)
 
 	card := player.cards[action.Index]
 	success := int(card.Value) == game.pileHeights[card.Color]+1
+	if !success {
+		game.discards = append(game.discards, card)
+	}
 	for _, o := range game.observers {
 		o.ObservePlay(game.currentPlayer, card, success)
 	}
+	for _, p := range game.playerStates {
+		if t, ok := p.strategy.(TableObserver); ok {
+			t.ObservePlay(game.currentPlayer, card, success)
+		}
+	}
 	if success {
 		// successful play
 		game.pileHeights[card.Color]++
+		if card.Value == 5 && game.rules.RefundBlueTokenOnFive && game.blueTokens < game.rules.MaxBlueTokens {
+			game.blueTokens++
+		}
 		won := true
 		for _, color := range ALL_COLORS {
 			if game.pileHeights[color] != 5 {
@@ -333,6 +418,33 @@ func (game *gameState) handlePlayAction(player *playerState, action *PlayAction)
 }
 
 func (game *gameState) Play() bool {
+	game.start()
+
+	for game.takeTurn() {
+	}
+
+	game.record.Won = game.won
+	game.record.Score = game.View().Score()
+
+	for _, o := range game.observers {
+		o.GameComplete(game.won, game.pileHeights)
+	}
+
+	return game.won
+}
+
+// Tells the players the game is starting, if they haven't been told already.
+func (game *gameState) start() {
+	if game.started {
+		return
+	}
+	game.started = true
+
+	for _, player := range game.playerStates {
+		if v, ok := player.strategy.(ViewObserver); ok {
+			v.ObserveView(game.View())
+		}
+	}
 
 	for i, player := range game.playerStates {
 		// TODO(mrjones): factor out duplicated code
@@ -347,19 +459,11 @@ func (game *gameState) Play() bool {
 		player.strategy.StartGame(
 			PlayerIndex(i), otherPlayersCards, len(player.cards), game.blueTokens, game.redTokens)
 	}
-
-	for game.takeTurn() {
-	}
-
-	for _, o := range game.observers {
-		o.GameComplete(game.won, game.pileHeights)
-	}
-
-	return game.won
 }
 
 func (game *gameState) takeTurn() bool {
 	player := game.playerStates[game.currentPlayer]
+	deckWasEmpty := len(game.drawPile) == 0
 
 	// TODO(mrjones): factor out duplicated code
 	otherPlayersCards := make(map[PlayerIndex][]Card)
@@ -370,13 +474,34 @@ func (game *gameState) takeTurn() bool {
 		}
 	}
 
-	action := player.strategy.Act(
-		game.currentPlayer, otherPlayersCards, len(player.cards), game.blueTokens, game.redTokens)
+	if cheater, ok := player.strategy.(CheatingStrategy); ok && game.cheating {
+		cheater.ObserveOwnCards(append([]Card{}, player.cards...))
+	}
+
+	var action Action
+	if turn := len(game.record.Turns); turn < len(game.history) {
+		action = game.history[turn].Action
+		if game.history[turn].Player != game.currentPlayer {
+			panic(fmt.Sprintf("Recorded for player %d, but it is player %d's turn",
+				game.history[turn].Player, game.currentPlayer))
+		}
+		if r, ok := player.strategy.(Rejoiner); ok {
+			r.Rejoin(game.currentPlayer, otherPlayersCards, len(player.cards), game.blueTokens, game.redTokens, action)
+		}
+	} else {
+		action = player.strategy.Act(
+			game.currentPlayer, otherPlayersCards, len(player.cards), game.blueTokens, game.redTokens)
+	}
 
 	if !action.IsValid() {
 		panic("Invalid action: " + action.InvalidReason())
 	}
 
+	game.record.Turns = append(game.record.Turns, TurnRecord{
+		Player: game.currentPlayer,
+		Action: action,
+	})
+
 	for _, o := range game.observers {
 		o.ObserveAction(game.currentPlayer, action)
 	}
@@ -399,6 +524,21 @@ func (game *gameState) takeTurn() bool {
 		}
 	}
 
+	// once the last card has been drawn, everyone gets one more turn
+	if keepGoing && deckWasEmpty {
+		game.finalTurns++
+		if game.finalTurns == len(game.playerStates) {
+			game.finished = true
+			game.won = false
+			keepGoing = kStop
+		}
+	}
+
+	view := game.View()
+	turn := &game.record.Turns[len(game.record.Turns)-1]
+	turn.MaxScore = view.MaxScore()
+	turn.Pace = view.Pace()
+
 	game.currentPlayer = PlayerIndex(
 		(int(game.currentPlayer) + 1) % len(game.playerStates))
 
@@ -406,60 +546,132 @@ func (game *gameState) takeTurn() bool {
 		o.TurnComplete(game.pileHeights, game.blueTokens, game.redTokens)
 	}
 
+	game.completedTurns++
 	return keepGoing
 }
 
+// Options for setting up a game. The zero value plays a standard game with
+// a randomly shuffled deck.
+type GameOptions struct {
+	// Seeds the shuffle and the choice of starting player, so that games can
+	// be reproduced. If zero, a seed is chosen from the current time (and is
+	// recorded in the GameRecord).
+	Seed int64
+
+	// If set, the deck to deal from, in order, instead of a shuffled one.
+	Deck []Card
+
+	// Allows CheatingStrategies to see their own cards. This should only be
+	// used for analysis, and the game's record is flagged accordingly.
+	AllowCheating bool
+
+	// The rules to play by. If nil, StandardRules are used.
+	Rules *Rules
+}
+
+// Returns the number of cards dealt to each player in a standard game with
+// numPlayers players.
+func HandSize(numPlayers int) (int, error) {
+	return StandardRules().HandSize(numPlayers)
+}
+
 func InitializeGame(players []PlayerStrategy, observers []Observer) (*gameState, error) {
-	deck := shuffle(createDeck())
+	return InitializeGameWithOptions(players, observers, GameOptions{})
+}
 
+func InitializeGameWithOptions(players []PlayerStrategy, observers []Observer, options GameOptions) (*gameState, error) {
 	numPlayers := len(players)
 
-	// map from number of players to number of initial cards per player
-	var kInitialCards = map[int]int{
-		2: 5,
-		3: 5,
-		4: 4,
-		5: 4,
+	rules := StandardRules()
+	if options.Rules != nil {
+		rules = options.Rules.copy()
 	}
 
-	cardsPerPlayer, ok := kInitialCards[numPlayers]
-	if !ok {
-		return nil, fmt.Errorf("Invalid number of players: %d", numPlayers)
+	if err := rules.Validate(numPlayers); err != nil {
+		return nil, err
+	}
+	cardsPerPlayer, _ := rules.HandSize(numPlayers)
+
+	for i, player := range players {
+		if _, ok := player.(CheatingStrategy); ok && !options.AllowCheating {
+			return nil, fmt.Errorf("Player %d can only play with GameOptions.AllowCheating", i)
+		}
+		if c, ok := player.(RulesChecker); ok {
+			if err := c.CheckRules(rules); err != nil {
+				return nil, fmt.Errorf("Player %d can't play by these rules: %v", i, err)
+			}
+		}
+	}
+
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UTC().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	deck := options.Deck
+	if deck == nil {
+		deck = shuffle(createDeck(), r)
+	}
+
+	if numPlayers*cardsPerPlayer > len(deck) {
+		return nil, fmt.Errorf("Can't deal %d players %d cards each from a deck of %d cards",
+			numPlayers, cardsPerPlayer, len(deck))
 	}
 
 	state := &gameState{
 		playerStates:  make([]*playerState, numPlayers),
 		pileHeights:   make(map[Color]int),
+		discards:      []Card{},
 		drawPile:      []Card{},
-		currentPlayer: PlayerIndex(rand.Intn(numPlayers)),
-		redTokens:     3,
-		blueTokens:    kMaxBlueTokens,
+		currentPlayer: PlayerIndex(r.Intn(numPlayers)),
+		redTokens:     rules.RedTokens,
+		blueTokens:    rules.MaxBlueTokens,
+		rules:         rules,
 		observers:     observers,
+		cheating:      options.AllowCheating,
 		finished:      false,
 		won:           false,
 	}
 
+	state.record = &GameRecord{
+		Seed:           seed,
+		Deck:           append([]Card{}, deck...),
+		NumPlayers:     numPlayers,
+		StartingPlayer: state.currentPlayer,
+		Cheating:       options.AllowCheating,
+		Rules:          rules.copy(),
+		Turns:          []TurnRecord{},
+	}
+
 	for i, _ := range ALL_COLORS {
 		state.pileHeights[Color(i)] = 0
 	}
 
+	state.knowledge = make([]HandKnowledge, numPlayers)
 	for p := PlayerIndex(0); int(p) < numPlayers; p++ {
 		state.playerStates[p] = &playerState{
 			cards:    []Card{},
 			strategy: players[p],
 		}
+		state.knowledge[p] = NewHandKnowledge(cardsPerPlayer)
 	}
 
 	drawCount := 0
 	for c := 0; c < cardsPerPlayer; c++ {
 		for p := PlayerIndex(0); int(p) < numPlayers; p++ {
-			// TODO(mrjones): bounds check
 			state.playerStates[p].cards = append(state.playerStates[p].cards, deck[drawCount])
 			drawCount++
 		}
 	}
 
+	state.drawPile = deck[drawCount:]
+
 	for _, o := range state.observers {
+		if v, ok := o.(ViewObserver); ok {
+			v.ObserveView(state.View())
+		}
+
 		cards := make([][]Card, len(state.playerStates))
 		for i, player := range state.playerStates {
 			cards[i] = player.cards
@@ -468,40 +680,60 @@ func InitializeGame(players []PlayerStrategy, observers []Observer) (*gameState,
 		o.GameStart(cards)
 	}
 
-	state.drawPile = deck[drawCount:]
 	return state, nil
 }
 
+// Returns a short description of the card, e.g. "R3".
+func (c Card) String() string {
+	if c.IsUnknown() {
+		return "??"
+	}
+	return fmt.Sprintf("%s%d", kColorInfos[c.Color].shortName, c.Value)
+}
+
 func DisplayDeck(deck []Card) {
 	for _, card := range deck {
 		fmt.Printf("color: %s, value: %d\n", kColorInfos[card.Color].fullName, card.Value)
 	}
 }
 
-func shuffle(in []Card) []Card {
-	rand.Seed(time.Now().UTC().UnixNano())
+// Returns the cards in a standard deck, sorted by color and then value.
+func NewDeck() []Card {
+	return createDeck()
+}
+
+// Returns a standard deck, shuffled using the given seed.
+func ShuffledDeck(seed int64) []Card {
+	return shuffle(createDeck(), rand.New(rand.NewSource(seed)))
+}
 
+func shuffle(in []Card, r *rand.Rand) []Card {
 	out := []Card{}
-	for _, i := range rand.Perm(len(in)) {
+	for _, i := range r.Perm(len(in)) {
 		out = append(out, in[i])
 	}
 
 	return out
 }
 
-func createDeck() []Card {
-	var kNumCardsInDeckByValue = map[Value]int{
-		1: 3,
-		2: 2,
-		3: 2,
-		4: 2,
-		5: 1,
-	}
+var kNumCardsInDeckByValue = map[Value]int{
+	1: 3,
+	2: 2,
+	3: 2,
+	4: 2,
+	5: 1,
+}
 
+// Returns the number of copies of a card with value v in each color.
+func NumCopies(v Value) int {
+	return kNumCardsInDeckByValue[v]
+}
+
+func createDeck() []Card {
 	cards := []Card{}
-	for color, _ := range kColorInfos {
-		for value, numCardsInDeckWithValue := range kNumCardsInDeckByValue {
-			for i := 0; i < numCardsInDeckWithValue; i++ {
+	for _, color := range ALL_COLORS {
+		for _, value := range ALL_VALUES {
+			for i := 0; i < kNumCardsInDeckByValue[value]; i++ {
 				cards = append(cards, Card{Value: value, Color: color})
 			}
 		}
@@ -0,0 +1,152 @@
+package yanhuo
+
+import (
+	"testing"
+)
+
+type firstCardPlayer struct{}
+
+func (p *firstCardPlayer) StartGame(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) {
+}
+
+func (p *firstCardPlayer) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	return Action{Play: &PlayAction{Index: 0}}
+}
+
+func (p *firstCardPlayer) ObserveAction(actor PlayerIndex, action Action) {
+}
+
+func playSeededGame(t *testing.T, seed int64) *GameRecord {
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}, &firstCardPlayer{}},
+		nil,
+		GameOptions{Seed: seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	return game.Record()
+}
+
+func TestSeededGamesAreReproducible(t *testing.T) {
+	r1 := playSeededGame(t, 42)
+	r2 := playSeededGame(t, 42)
+
+	if r1.StartingPlayer != r2.StartingPlayer {
+		t.Errorf("Starting players differ: %d vs %d", r1.StartingPlayer, r2.StartingPlayer)
+	}
+	if summarizeCards(r1.Deck) != summarizeCards(r2.Deck) {
+		t.Errorf("Decks differ:\n%s\n%s", summarizeCards(r1.Deck), summarizeCards(r2.Deck))
+	}
+	if len(r1.Turns) != len(r2.Turns) || r1.Score != r2.Score {
+		t.Errorf("Games played out differently: %d turns scoring %d vs %d turns scoring %d",
+			len(r1.Turns), r1.Score, len(r2.Turns), r2.Score)
+	}
+}
+
+func TestExplicitDeck(t *testing.T) {
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}},
+		nil,
+		GameOptions{Deck: NewDeck()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if game.playerStates[0].cards[0] != (Card{Color: WHITE, Value: 1}) {
+		t.Errorf("Expected the first card of the deck to be dealt first, got %s", game.playerStates[0].cards[0])
+	}
+}
+
+// Always discards its first card, and counts the cards revealed to it.
+type tableWatcher struct {
+	me       PlayerIndex
+	discards int
+	draws    map[PlayerIndex]int
+}
+
+func (w *tableWatcher) StartGame(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) {
+	w.me = me
+	w.draws = make(map[PlayerIndex]int)
+}
+
+func (w *tableWatcher) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	return Action{Discard: &DiscardAction{Index: 0}}
+}
+
+func (w *tableWatcher) ObserveAction(actor PlayerIndex, action Action) {}
+
+func (w *tableWatcher) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	w.discards++
+}
+
+func (w *tableWatcher) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	w.draws[p]++
+}
+
+func (w *tableWatcher) ObservePlay(p PlayerIndex, c Card, successful bool) {}
+
+type turnCountObserver struct {
+	BaseObserver
+	turns int
+	won   bool
+}
+
+func (o *turnCountObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	o.turns++
+}
+
+func (o *turnCountObserver) GameComplete(won bool, piles map[Color]int) {
+	o.won = won
+}
+
+func TestFinalTurns(t *testing.T) {
+	watchers := []*tableWatcher{{}, {}, {}}
+	o := &turnCountObserver{}
+	game, err := InitializeGame(
+		[]PlayerStrategy{watchers[0], watchers[1], watchers[2]},
+		[]Observer{o})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	// 35 of the 50 cards are left after the deal, and once the last one
+	// has been drawn, each player gets one more turn
+	if o.turns != 38 || o.won {
+		t.Errorf("Expected the game to be lost after 38 turns, got %d turns (won: %t)", o.turns, o.won)
+	}
+
+	draws := 0
+	for _, w := range watchers {
+		if w.discards != o.turns {
+			t.Errorf("Player %d saw %d discards in %d turns", w.me, w.discards, o.turns)
+		}
+		if w.draws[w.me] != 0 {
+			t.Errorf("Player %d saw %d of its own draws", w.me, w.draws[w.me])
+		}
+		for _, n := range w.draws {
+			draws += n
+		}
+	}
+	// each draw is seen by the two other players
+	if draws != 2*35 {
+		t.Errorf("Expected each draw to be seen twice, got %d sightings of 35 draws", draws)
+	}
+}
+
+func TestDrawReplacementWithEmptyDeck(t *testing.T) {
+	game, err := InitializeGame([]PlayerStrategy{&tableWatcher{}, &tableWatcher{}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.drawPile = nil
+	player := game.playerStates[game.currentPlayer]
+	before := append([]Card{}, player.cards...)
+
+	game.drawReplacement(player, 1)
+	expected := summarizeCards(append([]Card{before[0]}, before[2:]...))
+	if actual := summarizeCards(player.cards); actual != expected {
+		t.Errorf("Expected card 1 to be removed: %s, expected %s", actual, expected)
+	}
+}
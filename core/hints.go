@@ -0,0 +1,51 @@
+package yanhuo
+
+// The functions here are for standard games. See the methods on Rules for
+// games with variant suits.
+
+// Returns the information action telling player p about every card in cards
+// (that player's hand) which has color c.
+func NewColorHint(p PlayerIndex, cards []Card, c Color) *GiveInformationAction {
+	return Rules{}.ColorHint(p, cards, c)
+}
+
+// Returns the information action telling player p about every card in cards
+// (that player's hand) which has value v.
+func NewValueHint(p PlayerIndex, cards []Card, v Value) *GiveInformationAction {
+	return Rules{}.ValueHint(p, cards, v)
+}
+
+// Returns every hint which could be given to player p, holding cards: one
+// for each color and each value in the hand.
+func LegalHints(p PlayerIndex, cards []Card) []*GiveInformationAction {
+	return Rules{}.LegalHints(p, cards)
+}
+
+// HintEffect measures how much a hint told its recipient.
+type HintEffect struct {
+	// Cards touched by the hint which no earlier hint had touched.
+	NewlyTouched int
+	// How much the hint reduced the entropy of the recipient's hand, in bits
+	// (see HandKnowledge.Entropy).
+	EntropyReduction float64
+}
+
+// Returns the effect hint would have on a player whose knowledge about
+// their hand is h, without changing h.
+func MeasureHint(h HandKnowledge, hint *GiveInformationAction) HintEffect {
+	return MeasureHintWithRules(h, hint, Rules{})
+}
+
+// Like MeasureHint, but for a game played by the given rules.
+func MeasureHintWithRules(h HandKnowledge, hint *GiveInformationAction, rules Rules) HintEffect {
+	after := append(HandKnowledge{}, h...)
+	after.ApplyHintWithRules(hint, rules)
+
+	effect := HintEffect{EntropyReduction: h.Entropy() - after.Entropy()}
+	for i := range h {
+		if after[i].Touched && !h[i].Touched {
+			effect.NewlyTouched++
+		}
+	}
+	return effect
+}
@@ -0,0 +1,95 @@
+package yanhuo
+
+import (
+	"fmt"
+)
+
+// Restores the game described by record as it was after its first turns
+// turns, ready to continue with the given players: calling Play on the
+// result finishes the game. The earlier turns are played back by the engine
+// as they were recorded, so the players are started as usual and then see
+// them as if they had been at the table. Players which implement Rejoiner
+// are also told about their own earlier turns. Observers see every event,
+// including those of the earlier turns.
+func Rewind(record *GameRecord, turns int, players []PlayerStrategy, observers []Observer) (game *gameState, err error) {
+	if turns < 0 || turns > len(record.Turns) {
+		return nil, fmt.Errorf("Can't rewind to turn %d of a game with %d turns", turns, len(record.Turns))
+	}
+	if len(players) != record.NumPlayers {
+		return nil, fmt.Errorf("Game was played by %d players, but %d were given", record.NumPlayers, len(players))
+	}
+
+	options, err := record.options()
+	if err != nil {
+		return nil, err
+	}
+
+	game, err = InitializeGameWithOptions(players, observers, options)
+	if err != nil {
+		return nil, err
+	}
+	game.currentPlayer = record.StartingPlayer
+	game.record.StartingPlayer = record.StartingPlayer
+	game.history = append([]TurnRecord{}, record.Turns[:turns]...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Invalid record at turn %d: %v", len(game.record.Turns), r)
+			game = nil
+		}
+	}()
+
+	game.start()
+	for len(game.record.Turns) < turns {
+		if !game.takeTurn() {
+			return nil, fmt.Errorf("Game ended after %d turns, so can't be continued from turn %d", len(game.record.Turns), turns)
+		}
+	}
+	return game, nil
+}
+
+// GameTree holds a recorded game, along with the games branched from it:
+// games which were rewound to an earlier turn and then played differently.
+// It can be saved as JSON alongside the original record.
+type GameTree struct {
+	// Describes the branch, e.g. "discard instead of hinting".
+	Name string `json:",omitempty"`
+
+	// For a branch, the number of turns played before it diverged from its
+	// parent. The branch's record includes those turns.
+	Turn int
+
+	Record   *GameRecord
+	Branches []*GameTree `json:",omitempty"`
+}
+
+func NewGameTree(record *GameRecord) *GameTree {
+	return &GameTree{Record: record}
+}
+
+// Rewinds t's game to after its first turn turns, takes the given actions
+// in place of the recorded ones, and then has players finish the game. The
+// branched game is added to t's branches, and returned.
+func (t *GameTree) Branch(name string, turn int, actions []Action, players []PlayerStrategy, observers []Observer) (*GameTree, error) {
+	if turn < 0 || turn > len(t.Record.Turns) {
+		return nil, fmt.Errorf("Can't branch at turn %d of a game with %d turns", turn, len(t.Record.Turns))
+	}
+
+	script := *t.Record
+	script.Turns = append([]TurnRecord{}, t.Record.Turns[:turn]...)
+	for _, action := range actions {
+		player := (int(script.StartingPlayer) + len(script.Turns)) % script.NumPlayers
+		script.Turns = append(script.Turns, TurnRecord{Player: PlayerIndex(player), Action: action})
+	}
+
+	game, err := Rewind(&script, len(script.Turns), players, observers)
+	if err != nil {
+		return nil, err
+	}
+
+	game.Play()
+
+	branch := &GameTree{Name: name, Turn: turn, Record: game.Record()}
+	t.Branches = append(t.Branches, branch)
+	return branch, nil
+}
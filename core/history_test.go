@@ -0,0 +1,134 @@
+package yanhuo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// A firstCardHinter which counts the turns it rejoined.
+type turnCounter struct {
+	firstCardHinter
+	rejoined int
+}
+
+func (p *turnCounter) Rejoin(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int, action Action) {
+	p.rejoined++
+}
+
+func hinters(n int) []PlayerStrategy {
+	out := []PlayerStrategy{}
+	for i := 0; i < n; i++ {
+		out = append(out, &turnCounter{})
+	}
+	return out
+}
+
+func TestRewindContinuesGame(t *testing.T) {
+	game, err := InitializeGameWithOptions(hinters(3), nil, GameOptions{Seed: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	players := hinters(3)
+	tracker := NewBoardTracker()
+	rewound, err := Rewind(record, 10, players, []Observer{tracker})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracker.Boards) != 11 {
+		t.Errorf("Expected observers to see the first 10 turns, saw %d boards", len(tracker.Boards)-1)
+	}
+	rejoined := 0
+	for _, p := range players {
+		rejoined += p.(*turnCounter).rejoined
+	}
+	if rejoined != 10 {
+		t.Errorf("Expected players to rejoin 10 turns, got %d", rejoined)
+	}
+
+	rewound.Play()
+	continued := rewound.Record()
+
+	// the same deterministic players should finish the game the same way
+	if len(continued.Turns) != len(record.Turns) || continued.Score != record.Score {
+		t.Fatalf("Continued game took %d turns and scored %d, original took %d and scored %d",
+			len(continued.Turns), continued.Score, len(record.Turns), record.Score)
+	}
+	for i := range record.Turns {
+		if a, b := record.Turns[i].Action.DebugString(), continued.Turns[i].Action.DebugString(); a != b {
+			t.Errorf("Turn %d differs: %s vs %s", i, a, b)
+		}
+	}
+}
+
+func TestRewindRejectsBadRequests(t *testing.T) {
+	game, err := InitializeGameWithOptions(hinters(2), nil, GameOptions{Seed: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	if _, err := Rewind(record, len(record.Turns)+1, hinters(2), nil); err == nil {
+		t.Errorf("Expected an error rewinding past the end of the game")
+	}
+	if _, err := Rewind(record, len(record.Turns), hinters(2), nil); err == nil {
+		t.Errorf("Expected an error continuing a finished game")
+	}
+	if _, err := Rewind(record, 3, hinters(3), nil); err == nil {
+		t.Errorf("Expected an error rewinding with the wrong number of players")
+	}
+}
+
+func TestGameTreeBranch(t *testing.T) {
+	game, err := InitializeGameWithOptions(hinters(2), nil, GameOptions{Seed: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	tree := NewGameTree(game.Record())
+
+	discard := Action{Discard: &DiscardAction{Index: 2}}
+	branch, err := tree.Branch("discard instead", 5, []Action{discard}, hinters(2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Branches) != 1 || branch.Turn != 5 {
+		t.Fatalf("Branch not added to tree: %+v", tree)
+	}
+	for i := 0; i < 5; i++ {
+		if a, b := tree.Record.Turns[i].Action.DebugString(), branch.Record.Turns[i].Action.DebugString(); a != b {
+			t.Errorf("Turn %d should be shared by the branch: %s vs %s", i, a, b)
+		}
+	}
+	if branch.Record.Turns[5].Action.Discard == nil || branch.Record.Turns[5].Player != tree.Record.Turns[5].Player {
+		t.Errorf("Branch should discard at turn 5: %s", branch.Record.Turns[5].Action.DebugString())
+	}
+
+	// branches of branches
+	if _, err := branch.Branch("hint again", 7, nil, hinters(2), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded GameTree
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Branches) != 1 || len(decoded.Branches[0].Branches) != 1 || decoded.Branches[0].Name != "discard instead" {
+		t.Errorf("Tree didn't survive JSON: %s", string(data))
+	}
+	if err := Replay(decoded.Branches[0].Branches[0].Record, nil); err != nil {
+		t.Errorf("Couldn't replay a saved branch: %v", err)
+	}
+
+	badHint := Action{GiveInformation: &GiveInformationAction{PlayerIndex: 1, Cards: []HandIndex{0, 1, 2, 3, 4}, Value: &ValueInformation{Value: 1}}}
+	if _, err := tree.Branch("bad", 0, []Action{badHint}, hinters(2), nil); err == nil {
+		t.Errorf("Expected an error branching with an illegal action")
+	}
+}
@@ -46,3 +46,24 @@ func (vi *ValueInformation) UnmarshalJSON(data []byte) error {
 	vi.Value = v
 	return nil
 }
+
+// Colors are also used as map keys (e.g. for piles), which need to be
+// encoded as text.
+func (c Color) MarshalText() ([]byte, error) {
+	info, ok := kColorInfos[c]
+	if !ok {
+		return nil, fmt.Errorf("invalid color %d", c)
+	}
+	return []byte(info.fullName), nil
+}
+
+func (c *Color) UnmarshalText(data []byte) error {
+	for color, colorInfo := range kColorInfos {
+		if string(data) == colorInfo.fullName {
+			*c = color
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid color %q", data)
+}
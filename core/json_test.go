@@ -58,3 +58,25 @@ func TestRoundTrips_Action(t *testing.T) {
 }
 
 
+
+func TestRoundTrips_Piles(t *testing.T) {
+	piles := map[Color]int{RED: 2, GREEN: 5}
+
+	data, err := json.Marshal(piles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\"GREEN\":5,\"RED\":2}"
+	if string(data) != expected {
+		t.Errorf("Expected JSON:\n%s\nActual JSON:\n%s", expected, string(data))
+	}
+
+	var parsed map[Color]int
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed[RED] != 2 || parsed[GREEN] != 5 || len(parsed) != 2 {
+		t.Errorf("Round-tripped piles don't match: %v", parsed)
+	}
+}
@@ -0,0 +1,98 @@
+package yanhuo
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// One line of the log written by JSONLinesObserver. Only the fields which
+// apply to the event are set.
+type JSONEvent struct {
+	GameID string
+	Turn   int
+	// One of "GameStart", "Action", "Draw", "Discard", "Play",
+	// "TurnComplete" or "GameComplete"
+	Event string
+
+	Player     *PlayerIndex  `json:",omitempty"`
+	Hands      [][]Card      `json:",omitempty"`
+	Action     *Action       `json:",omitempty"`
+	Card       *Card         `json:",omitempty"`
+	Index      *HandIndex    `json:",omitempty"`
+	Successful *bool         `json:",omitempty"`
+	Piles      map[Color]int `json:",omitempty"`
+	BlueTokens *int          `json:",omitempty"`
+	RedTokens  *int          `json:",omitempty"`
+	Won        *bool         `json:",omitempty"`
+	Score      *int          `json:",omitempty"`
+}
+
+// JSONLinesObserver writes every event in a game as a JSON object on its
+// own line, for loading into analysis tools.
+type JSONLinesObserver struct {
+	encoder *json.Encoder
+	gameID  string
+	view    *View
+	turn    int
+	err     error
+}
+
+// Returns an observer which writes to w, tagging every event with gameID.
+func NewJSONLinesObserver(w io.Writer, gameID string) *JSONLinesObserver {
+	return &JSONLinesObserver{encoder: json.NewEncoder(w), gameID: gameID}
+}
+
+// Returns the first error encountered writing events, if any. Nothing more
+// is written after an error.
+func (o *JSONLinesObserver) Err() error {
+	return o.err
+}
+
+// The final score is counted by the game's rules if the observer is given a
+// View, and by the standard rules otherwise.
+func (o *JSONLinesObserver) ObserveView(v *View) {
+	o.view = v
+}
+
+func (o *JSONLinesObserver) write(e JSONEvent) {
+	if o.err != nil {
+		return
+	}
+	e.GameID = o.gameID
+	e.Turn = o.turn
+	o.err = o.encoder.Encode(&e)
+}
+
+func (o *JSONLinesObserver) GameStart(cards [][]Card) {
+	o.turn = 0
+	o.write(JSONEvent{Event: "GameStart", Hands: cards})
+}
+
+func (o *JSONLinesObserver) ObserveAction(p PlayerIndex, a Action) {
+	o.write(JSONEvent{Event: "Action", Player: &p, Action: &a})
+}
+
+func (o *JSONLinesObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	o.write(JSONEvent{Event: "Discard", Player: &p, Card: &c, Index: &i})
+}
+
+func (o *JSONLinesObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	o.write(JSONEvent{Event: "Draw", Player: &p, Card: &c, Index: &i})
+}
+
+func (o *JSONLinesObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	o.write(JSONEvent{Event: "Play", Player: &p, Card: &c, Successful: &successful})
+}
+
+func (o *JSONLinesObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	o.write(JSONEvent{Event: "TurnComplete", Piles: piles, BlueTokens: &blueTokens, RedTokens: &redTokens})
+	o.turn++
+}
+
+func (o *JSONLinesObserver) GameComplete(won bool, piles map[Color]int) {
+	score := Score(piles)
+	if o.view != nil {
+		score = o.view.Score()
+	}
+	o.write(JSONEvent{Event: "GameComplete", Won: &won, Piles: piles, Score: &score})
+}
@@ -0,0 +1,87 @@
+package yanhuo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONLinesObserver(t *testing.T) {
+	var buffer bytes.Buffer
+	o := NewJSONLinesObserver(&buffer, "game-1")
+
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardPlayer{}},
+		[]Observer{o},
+		GameOptions{Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	if o.Err() != nil {
+		t.Fatal(o.Err())
+	}
+
+	counts := map[string]int{}
+	lastTurn := 0
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var e JSONEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Couldn't parse line %q: %s", scanner.Text(), err)
+		}
+
+		if e.GameID != "game-1" {
+			t.Errorf("Wrong game ID on line %q", scanner.Text())
+		}
+		if e.Turn < lastTurn {
+			t.Errorf("Turn went backwards on line %q", scanner.Text())
+		}
+		lastTurn = e.Turn
+		counts[e.Event]++
+
+		if e.Event == "Play" && (e.Card == nil || e.Successful == nil || e.Card.IsUnknown()) {
+			t.Errorf("Play event is missing its card: %q", scanner.Text())
+		}
+		if e.Event == "TurnComplete" && (e.BlueTokens == nil || e.Piles == nil) {
+			t.Errorf("TurnComplete event is missing the table state: %q", scanner.Text())
+		}
+	}
+
+	record := game.Record()
+	if counts["GameStart"] != 1 || counts["GameComplete"] != 1 {
+		t.Errorf("Expected exactly one start and end, got %v", counts)
+	}
+	if counts["Action"] != len(record.Turns) || counts["TurnComplete"] != len(record.Turns) {
+		t.Errorf("Expected %d actions and turns, got %v", len(record.Turns), counts)
+	}
+	if lastTurn != len(record.Turns) {
+		t.Errorf("Expected the last event on turn %d, got %d", len(record.Turns), lastTurn)
+	}
+}
+
+func TestJSONLinesObserverScoresByRules(t *testing.T) {
+	var buffer bytes.Buffer
+	o := NewJSONLinesObserver(&buffer, "game-1")
+
+	rules := StandardRules()
+	rules.StrikeOutScoresZero = true
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}},
+		[]Observer{o},
+		GameOptions{Seed: 5, Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	var last JSONEvent
+	if err := json.Unmarshal(lines[len(lines)-1], &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Event != "GameComplete" || last.Score == nil || *last.Score != 0 || Score(last.Piles) == 0 {
+		t.Errorf("Expected a strike-out to score 0 despite the piles: %+v", last)
+	}
+}
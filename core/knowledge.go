@@ -0,0 +1,203 @@
+package yanhuo
+
+import (
+	"math"
+)
+
+var ALL_VALUES = []Value{1, 2, 3, 4, 5}
+
+// CardKnowledge is what the holder of a card has been told about it by
+// hints: which cards it could still be, and whether any hint has touched it.
+type CardKnowledge struct {
+	// One bit per card (see cardBit)
+	cards uint32
+
+	Touched bool
+}
+
+func cardBit(c Card) uint32 {
+	return 1 << uint(int(c.Color)*len(ALL_VALUES)+int(c.Value)-1)
+}
+
+// Returns the knowledge about a card which no hint has referred to yet.
+func NewCardKnowledge() CardKnowledge {
+	k := CardKnowledge{}
+	for _, c := range ALL_COLORS {
+		for _, v := range ALL_VALUES {
+			k.cards |= cardBit(Card{Color: c, Value: v})
+		}
+	}
+	return k
+}
+
+func (k CardKnowledge) CanBe(c Card) bool {
+	return k.cards&cardBit(c) != 0
+}
+
+func (k CardKnowledge) CanBeColor(c Color) bool {
+	for _, v := range ALL_VALUES {
+		if k.CanBe(Card{Color: c, Value: v}) {
+			return true
+		}
+	}
+	return false
+}
+
+func (k CardKnowledge) CanBeValue(v Value) bool {
+	for _, c := range ALL_COLORS {
+		if k.CanBe(Card{Color: c, Value: v}) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the card's color, if it is the only one possible.
+func (k CardKnowledge) Color() (Color, bool) {
+	colors := k.PossibleColors()
+	if len(colors) != 1 {
+		return 0, false
+	}
+	return colors[0], true
+}
+
+// Returns the card's value, if it is the only one possible.
+func (k CardKnowledge) Value() (Value, bool) {
+	values := k.PossibleValues()
+	if len(values) != 1 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+func (k CardKnowledge) PossibleColors() []Color {
+	colors := []Color{}
+	for _, c := range ALL_COLORS {
+		if k.CanBeColor(c) {
+			colors = append(colors, c)
+		}
+	}
+	return colors
+}
+
+func (k CardKnowledge) PossibleValues() []Value {
+	values := []Value{}
+	for _, v := range ALL_VALUES {
+		if k.CanBeValue(v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Returns every card this could be, ignoring how many copies of each remain.
+func (k CardKnowledge) PossibleCards() []Card {
+	cards := []Card{}
+	for _, c := range ALL_COLORS {
+		for _, v := range ALL_VALUES {
+			if card := (Card{Color: c, Value: v}); k.CanBe(card) {
+				cards = append(cards, card)
+			}
+		}
+	}
+	return cards
+}
+
+// Narrows the knowledge using a hint given to the card's holder, in a
+// standard game. touched says whether the hint referred to this card: if it
+// did the card must match the hint, otherwise it must not.
+func (k *CardKnowledge) ApplyHint(hint *GiveInformationAction, touched bool) {
+	k.ApplyHintWithRules(hint, touched, Rules{})
+}
+
+// Like ApplyHint, but for a game played by the given rules, where hints may
+// touch some suits differently.
+func (k *CardKnowledge) ApplyHintWithRules(hint *GiveInformationAction, touched bool, rules Rules) {
+	for _, c := range k.PossibleCards() {
+		if rules.Touches(hint, c) != touched {
+			k.cards &^= cardBit(c)
+		}
+	}
+
+	if touched {
+		k.Touched = true
+	}
+}
+
+// HandKnowledge is the knowledge about each card in a player's hand, indexed
+// the same way as the hand.
+type HandKnowledge []CardKnowledge
+
+func NewHandKnowledge(numCards int) HandKnowledge {
+	h := make(HandKnowledge, numCards)
+	for i := range h {
+		h[i] = NewCardKnowledge()
+	}
+	return h
+}
+
+// Applies a hint given to the player holding this hand, in a standard game.
+func (h HandKnowledge) ApplyHint(hint *GiveInformationAction) {
+	h.ApplyHintWithRules(hint, Rules{})
+}
+
+// Like ApplyHint, but for a game played by the given rules.
+func (h HandKnowledge) ApplyHintWithRules(hint *GiveInformationAction, rules Rules) {
+	for i := range h {
+		touched := false
+		for _, j := range hint.Cards {
+			if int(j) == i {
+				touched = true
+			}
+		}
+		h[i].ApplyHintWithRules(hint, touched, rules)
+	}
+}
+
+// Updates the knowledge after the card at index i left the hand. If another
+// card was drawn it takes the same index, so nothing is known about it;
+// otherwise the hand shrinks.
+func (h HandKnowledge) Replace(i HandIndex, drew bool) HandKnowledge {
+	if drew {
+		h[i] = NewCardKnowledge()
+		return h
+	}
+	return append(h[:i], h[i+1:]...)
+}
+
+// Returns a short description of what is known about the card, in the same
+// form as Card.String: e.g. "R?" for a card known to be red, or "??" if
+// neither its color nor its value is known.
+func (k CardKnowledge) String() string {
+	s := "?"
+	if c, ok := k.Color(); ok {
+		s = kColorInfos[c].shortName
+	}
+	if v, ok := k.Value(); ok {
+		return s + string('0'+rune(v))
+	}
+	return s + "?"
+}
+
+// Returns the entropy, in bits, of what the card could be: log2 of the
+// number of cards in the deck it could be, ignoring which have been seen.
+func (k CardKnowledge) Entropy() float64 {
+	copies := 0
+	for _, c := range k.PossibleCards() {
+		copies += NumCopies(c.Value)
+	}
+	if copies == 0 {
+		return 0
+	}
+	return math.Log2(float64(copies))
+}
+
+// Returns the total entropy of the cards in the hand (see
+// CardKnowledge.Entropy).
+func (h HandKnowledge) Entropy() float64 {
+	total := 0.0
+	for _, k := range h {
+		total += k.Entropy()
+	}
+	return total
+}
@@ -5,28 +5,71 @@ import (
 	"log"
 )
 
+// BaseObserver ignores every event. Embed it in an Observer to only
+// implement the callbacks you need.
+type BaseObserver struct{}
+
+func (o BaseObserver) GameStart(cards [][]Card)                                        {}
+func (o BaseObserver) ObserveAction(p PlayerIndex, a Action)                           {}
+func (o BaseObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex)               {}
+func (o BaseObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex)                  {}
+func (o BaseObserver) ObservePlay(p PlayerIndex, c Card, successful bool)              {}
+func (o BaseObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {}
+func (o BaseObserver) GameComplete(won bool, piles map[Color]int)                      {}
+
 type LoggingObserver struct {
+	// If set, the whole board is logged after every turn.
+	ShowBoard bool
+	Render    RenderOptions
+
+	tracker *BoardTracker
+	view    *View
+}
+
+// Passes the game's rules on to the board, which otherwise assumes the
+// standard rules.
+func (o *LoggingObserver) ObserveView(v *View) {
+	o.view = v
+	if o.tracker != nil {
+		o.tracker.ObserveView(v)
+	}
 }
 
 func (o *LoggingObserver) ObserveAction(p PlayerIndex, a Action) {
+	if o.tracker != nil {
+		o.tracker.ObserveAction(p, a)
+	}
 	log.Printf("Player %d taking action '%s'\n", p, a.DebugString())
 }
 
 func (o *LoggingObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	if o.tracker != nil {
+		o.tracker.ObserveDiscard(p, c, i)
+	}
 	log.Printf("Player %d discards a %s %d\n", p, kColorInfos[c.Color].fullName, c.Value)
 }
 
 func (o *LoggingObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	if o.tracker != nil {
+		o.tracker.ObserveDraw(p, c, i)
+	}
 	log.Printf("Player %d drew a %s %d\n", p, kColorInfos[c.Color].fullName, c.Value)
 }
 
 func (o *LoggingObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	if o.tracker != nil {
+		o.tracker.ObservePlay(p, c, successful)
+	}
 	log.Printf("Player %d played a %s %d. Successful: %t\n", p,
 		kColorInfos[c.Color].fullName, c.Value, successful)
 }
 
 func (o *LoggingObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
 	log.Printf("Turn complete.\n")
+	if o.tracker != nil {
+		o.tracker.TurnComplete(piles, blueTokens, redTokens)
+		log.Printf("\n%s", o.tracker.Board().Render(o.Render))
+	}
 	log.Printf("---\n")
 }
 
@@ -39,6 +82,14 @@ func (o *LoggingObserver) GameComplete(won bool, piles map[Color]int) {
 }
 
 func (o *LoggingObserver) GameStart(cards [][]Card) {
+	o.tracker = nil
+	if o.ShowBoard {
+		o.tracker = NewBoardTracker()
+		if o.view != nil {
+			o.tracker.ObserveView(o.view)
+		}
+		o.tracker.GameStart(cards)
+	}
 	for i, playerCards := range cards {
 		log.Printf("Player %d: [%s]\n", i, summarizeCards(playerCards))
 	}
@@ -50,7 +101,7 @@ func summarizeCards(cards []Card) string {
 	sep := ""
 
 	for _, c := range cards {
-		a = fmt.Sprintf("%s%s%s%d", a, sep, kColorInfos[c.Color].shortName, c.Value)
+		a = fmt.Sprintf("%s%s%s", a, sep, c)
 		sep = ", "
 	}
 
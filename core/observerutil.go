@@ -0,0 +1,232 @@
+package yanhuo
+
+import (
+	"sync"
+)
+
+// MultiObserver passes every event to each of its observers, in order.
+type MultiObserver []Observer
+
+func NewMultiObserver(observers ...Observer) MultiObserver {
+	return MultiObserver(observers)
+}
+
+// Passes the View on to each observer which is a ViewObserver.
+func (m MultiObserver) ObserveView(v *View) {
+	for _, o := range m {
+		if vo, ok := o.(ViewObserver); ok {
+			vo.ObserveView(v)
+		}
+	}
+}
+
+func (m MultiObserver) GameStart(cards [][]Card) {
+	for _, o := range m {
+		o.GameStart(cards)
+	}
+}
+
+func (m MultiObserver) ObserveAction(p PlayerIndex, a Action) {
+	for _, o := range m {
+		o.ObserveAction(p, a)
+	}
+}
+
+func (m MultiObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	for _, o := range m {
+		o.ObserveDiscard(p, c, i)
+	}
+}
+
+func (m MultiObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	for _, o := range m {
+		o.ObserveDraw(p, c, i)
+	}
+}
+
+func (m MultiObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	for _, o := range m {
+		o.ObservePlay(p, c, successful)
+	}
+}
+
+func (m MultiObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	for _, o := range m {
+		o.TurnComplete(piles, blueTokens, redTokens)
+	}
+}
+
+func (m MultiObserver) GameComplete(won bool, piles map[Color]int) {
+	for _, o := range m {
+		o.GameComplete(won, piles)
+	}
+}
+
+// Identifies kinds of Observer events, for NewFilteringObserver. Combine
+// them with |.
+type EventType uint
+
+const (
+	GameStartEvent EventType = 1 << iota
+	ActionEvent
+	DiscardEvent
+	DrawEvent
+	PlayEvent
+	TurnCompleteEvent
+	GameCompleteEvent
+
+	AllEvents = GameStartEvent | ActionEvent | DiscardEvent | DrawEvent |
+		PlayEvent | TurnCompleteEvent | GameCompleteEvent
+)
+
+type filteringObserver struct {
+	observer Observer
+	events   EventType
+}
+
+// Returns an Observer which only passes on the given types of event to o.
+func NewFilteringObserver(o Observer, events EventType) Observer {
+	return &filteringObserver{observer: o, events: events}
+}
+
+func (f *filteringObserver) ObserveView(v *View) {
+	if vo, ok := f.observer.(ViewObserver); ok {
+		vo.ObserveView(v)
+	}
+}
+
+func (f *filteringObserver) GameStart(cards [][]Card) {
+	if f.events&GameStartEvent != 0 {
+		f.observer.GameStart(cards)
+	}
+}
+
+func (f *filteringObserver) ObserveAction(p PlayerIndex, a Action) {
+	if f.events&ActionEvent != 0 {
+		f.observer.ObserveAction(p, a)
+	}
+}
+
+func (f *filteringObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	if f.events&DiscardEvent != 0 {
+		f.observer.ObserveDiscard(p, c, i)
+	}
+}
+
+func (f *filteringObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	if f.events&DrawEvent != 0 {
+		f.observer.ObserveDraw(p, c, i)
+	}
+}
+
+func (f *filteringObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	if f.events&PlayEvent != 0 {
+		f.observer.ObservePlay(p, c, successful)
+	}
+}
+
+func (f *filteringObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	if f.events&TurnCompleteEvent != 0 {
+		f.observer.TurnComplete(piles, blueTokens, redTokens)
+	}
+}
+
+func (f *filteringObserver) GameComplete(won bool, piles map[Color]int) {
+	if f.events&GameCompleteEvent != 0 {
+		f.observer.GameComplete(won, piles)
+	}
+}
+
+// AsyncObserver passes events on to another Observer from a separate
+// goroutine, so that a slow observer doesn't hold up the game. Events are
+// delivered in order. Up to bufferSize events are queued; after that the
+// game waits for the observer to catch up.
+//
+// Call Flush to wait for queued events to be delivered, and Close once the
+// game is over. No events may be sent after Close.
+//
+// The wrapped observer is never given a View, since it would be reading the
+// game's state from another goroutine.
+type AsyncObserver struct {
+	observer Observer
+	events   chan func()
+	done     chan struct{}
+	close    sync.Once
+}
+
+func NewAsyncObserver(o Observer, bufferSize int) *AsyncObserver {
+	a := &AsyncObserver{
+		observer: o,
+		events:   make(chan func(), bufferSize),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AsyncObserver) run() {
+	for event := range a.events {
+		event()
+	}
+	close(a.done)
+}
+
+// Waits until every event sent so far has been delivered.
+func (a *AsyncObserver) Flush() {
+	flushed := make(chan struct{})
+	a.events <- func() { close(flushed) }
+	<-flushed
+}
+
+// Delivers any queued events and stops the delivery goroutine.
+func (a *AsyncObserver) Close() {
+	a.close.Do(func() {
+		close(a.events)
+	})
+	<-a.done
+}
+
+// The engine reuses the slices and maps it passes to observers, so we take
+// copies of them before handing them to another goroutine.
+
+func copyPiles(piles map[Color]int) map[Color]int {
+	out := make(map[Color]int, len(piles))
+	for c, height := range piles {
+		out[c] = height
+	}
+	return out
+}
+
+func (a *AsyncObserver) GameStart(cards [][]Card) {
+	copied := make([][]Card, len(cards))
+	for i, playerCards := range cards {
+		copied[i] = append([]Card{}, playerCards...)
+	}
+	a.events <- func() { a.observer.GameStart(copied) }
+}
+
+func (a *AsyncObserver) ObserveAction(p PlayerIndex, action Action) {
+	a.events <- func() { a.observer.ObserveAction(p, action) }
+}
+
+func (a *AsyncObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	a.events <- func() { a.observer.ObserveDiscard(p, c, i) }
+}
+
+func (a *AsyncObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	a.events <- func() { a.observer.ObserveDraw(p, c, i) }
+}
+
+func (a *AsyncObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	a.events <- func() { a.observer.ObservePlay(p, c, successful) }
+}
+
+func (a *AsyncObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	copied := copyPiles(piles)
+	a.events <- func() { a.observer.TurnComplete(copied, blueTokens, redTokens) }
+}
+
+func (a *AsyncObserver) GameComplete(won bool, piles map[Color]int) {
+	copied := copyPiles(piles)
+	a.events <- func() { a.observer.GameComplete(won, copied) }
+}
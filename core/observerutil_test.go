@@ -0,0 +1,77 @@
+package yanhuo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Records a line for each play and turn, and nothing else.
+type eventLog struct {
+	BaseObserver
+	events []string
+	delay  time.Duration
+}
+
+func (o *eventLog) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	time.Sleep(o.delay)
+	o.events = append(o.events, fmt.Sprintf("play %d %s", p, c))
+}
+
+func (o *eventLog) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	time.Sleep(o.delay)
+	o.events = append(o.events, fmt.Sprintf("turn %d", Score(piles)))
+}
+
+func sendEvents(o Observer) {
+	piles := map[Color]int{}
+	o.GameStart([][]Card{{}, {}})
+	for i := 0; i < 5; i++ {
+		o.ObserveAction(PlayerIndex(i%2), Action{Play: &PlayAction{Index: 0}})
+		o.ObservePlay(PlayerIndex(i%2), Card{Color: RED, Value: Value(i + 1)}, true)
+		piles[RED]++
+		o.TurnComplete(piles, 8, 3)
+	}
+	o.GameComplete(true, piles)
+}
+
+func TestMultiObserver(t *testing.T) {
+	a, b := &eventLog{}, &eventLog{}
+	sendEvents(NewMultiObserver(a, b))
+
+	if len(a.events) != 10 || strings.Join(a.events, ",") != strings.Join(b.events, ",") {
+		t.Errorf("Both observers should see all events:\n%v\n%v", a.events, b.events)
+	}
+}
+
+func TestFilteringObserver(t *testing.T) {
+	o := &eventLog{}
+	sendEvents(NewFilteringObserver(o, PlayEvent))
+
+	if len(o.events) != 5 {
+		t.Errorf("Expected only the 5 plays, got %v", o.events)
+	}
+	for _, e := range o.events {
+		if !strings.HasPrefix(e, "play") {
+			t.Errorf("Unexpected event: %s", e)
+		}
+	}
+}
+
+func TestAsyncObserverPreservesOrder(t *testing.T) {
+	sync, slow := &eventLog{}, &eventLog{delay: time.Millisecond}
+	sendEvents(sync)
+
+	async := NewAsyncObserver(slow, 2)
+	sendEvents(async)
+	async.Flush()
+	if len(slow.events) != len(sync.events) {
+		t.Errorf("Flush should deliver every event, but only %d of %d arrived", len(slow.events), len(sync.events))
+	}
+	async.Close()
+
+	if strings.Join(slow.events, ",") != strings.Join(sync.events, ",") {
+		t.Errorf("Events arrived out of order:\n%v\nexpected:\n%v", slow.events, sync.events)
+	}
+}
@@ -0,0 +1,68 @@
+package yanhuo
+
+// Stands in for a card whose identity is hidden from an observer.
+var UnknownCard = Card{}
+
+// Whether this card's identity is hidden (see UnknownCard).
+func (c Card) IsUnknown() bool {
+	return c.Value == 0
+}
+
+type perspectiveObserver struct {
+	player   PlayerIndex
+	observer Observer
+}
+
+// Returns an Observer which passes on to o only what player p is allowed to
+// see: p's own cards are UnknownCards, both in the initial deal and when p
+// draws. Everything else is public, and is passed on unchanged. This makes
+// it safe to send the event stream to a client for that seat.
+func NewPerspectiveObserver(p PlayerIndex, o Observer) Observer {
+	return &perspectiveObserver{player: p, observer: o}
+}
+
+// The View only shows public information, so it can be passed on.
+func (o *perspectiveObserver) ObserveView(v *View) {
+	if vo, ok := o.observer.(ViewObserver); ok {
+		vo.ObserveView(v)
+	}
+}
+
+func (o *perspectiveObserver) GameStart(cards [][]Card) {
+	masked := make([][]Card, len(cards))
+	for i, playerCards := range cards {
+		if PlayerIndex(i) == o.player {
+			masked[i] = make([]Card, len(playerCards))
+		} else {
+			masked[i] = append([]Card{}, playerCards...)
+		}
+	}
+	o.observer.GameStart(masked)
+}
+
+func (o *perspectiveObserver) ObserveAction(p PlayerIndex, a Action) {
+	o.observer.ObserveAction(p, a)
+}
+
+func (o *perspectiveObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	o.observer.ObserveDiscard(p, c, i)
+}
+
+func (o *perspectiveObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	if p == o.player {
+		c = UnknownCard
+	}
+	o.observer.ObserveDraw(p, c, i)
+}
+
+func (o *perspectiveObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	o.observer.ObservePlay(p, c, successful)
+}
+
+func (o *perspectiveObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	o.observer.TurnComplete(piles, blueTokens, redTokens)
+}
+
+func (o *perspectiveObserver) GameComplete(won bool, piles map[Color]int) {
+	o.observer.GameComplete(won, piles)
+}
@@ -0,0 +1,72 @@
+package yanhuo
+
+import (
+	"testing"
+)
+
+type drawRecorder struct {
+	hands [][]Card
+	draws map[PlayerIndex][]Card
+}
+
+func (o *drawRecorder) GameStart(cards [][]Card) {
+	for _, playerCards := range cards {
+		o.hands = append(o.hands, append([]Card{}, playerCards...))
+	}
+	o.draws = map[PlayerIndex][]Card{}
+}
+
+func (o *drawRecorder) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	o.draws[p] = append(o.draws[p], c)
+}
+
+func (o *drawRecorder) ObserveAction(p PlayerIndex, a Action)                           {}
+func (o *drawRecorder) ObserveDiscard(p PlayerIndex, c Card, i HandIndex)               {}
+func (o *drawRecorder) ObservePlay(p PlayerIndex, c Card, successful bool)              {}
+func (o *drawRecorder) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {}
+func (o *drawRecorder) GameComplete(won bool, piles map[Color]int)                      {}
+
+type firstCardDiscarder struct {
+	firstCardPlayer
+}
+
+func (p *firstCardDiscarder) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	return Action{Discard: &DiscardAction{Index: 0}}
+}
+
+func TestPerspectiveHidesOwnCards(t *testing.T) {
+	everything := &drawRecorder{}
+	seat1 := &drawRecorder{}
+
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}, &firstCardDiscarder{}},
+		[]Observer{everything, NewPerspectiveObserver(1, seat1)},
+		GameOptions{Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	for i, c := range seat1.hands[1] {
+		if !c.IsUnknown() {
+			t.Errorf("Seat 1 could see its own card %d: %s", i, c)
+		}
+	}
+	for _, c := range seat1.draws[1] {
+		if !c.IsUnknown() {
+			t.Errorf("Seat 1 could see its own draw: %s", c)
+		}
+	}
+
+	if summarizeCards(seat1.hands[0]) != summarizeCards(everything.hands[0]) {
+		t.Errorf("Seat 1 should see player 0's cards (%s), but saw %s",
+			summarizeCards(everything.hands[0]), summarizeCards(seat1.hands[0]))
+	}
+	if summarizeCards(seat1.draws[2]) != summarizeCards(everything.draws[2]) {
+		t.Errorf("Seat 1 should see player 2's draws (%s), but saw %s",
+			summarizeCards(everything.draws[2]), summarizeCards(seat1.draws[2]))
+	}
+	if len(everything.draws[1]) == 0 {
+		t.Errorf("Expected player 1 to draw at least once")
+	}
+}
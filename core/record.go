@@ -0,0 +1,79 @@
+package yanhuo
+
+// GameRecord holds everything needed to reproduce a game: the deck it was
+// dealt from, who started, and every action taken.
+type GameRecord struct {
+	Seed           int64
+	Deck           []Card
+	NumPlayers     int
+	StartingPlayer PlayerIndex
+
+	// Set if strategies were allowed to see their own cards, in which case
+	// the score says nothing about how well they play.
+	Cheating bool `json:",omitempty"`
+
+	// Zero in records made before rules were recorded (see PlayedRules).
+	Rules Rules
+
+	Turns []TurnRecord
+
+	Won   bool
+	Score int
+}
+
+// Returns the rules the game was played by. Records made before rules were
+// recorded have zero Rules, and were played by the standard rules.
+func (record *GameRecord) PlayedRules() Rules {
+	if record.Rules.IsZero() {
+		return StandardRules()
+	}
+	return record.Rules.copy()
+}
+
+type TurnRecord struct {
+	Player PlayerIndex
+	Action Action
+
+	// The best score still possible, and the pace (see View.Pace), once the
+	// action had been resolved.
+	MaxScore int
+	Pace     int
+
+	// For hints, what the hint told its recipient.
+	Hint *HintEffect `json:",omitempty"`
+}
+
+// Returns the record of the game so far.
+func (game *gameState) Record() *GameRecord {
+	r := *game.record
+	r.Deck = append([]Card{}, r.Deck...)
+	r.Turns = append([]TurnRecord{}, r.Turns...)
+	r.Rules = r.Rules.copy()
+	return &r
+}
+
+// Returns the number of cards newly touched per hint given over the game,
+// a measure of how efficiently the players used their hints.
+func (r *GameRecord) HintEfficiency() float64 {
+	hints, touched := 0, 0
+	for _, turn := range r.Turns {
+		if turn.Hint != nil {
+			hints++
+			touched += turn.Hint.NewlyTouched
+		}
+	}
+	if hints == 0 {
+		return 0
+	}
+	return float64(touched) / float64(hints)
+}
+
+// Returns the score for a game which ended with the given piles: the number
+// of cards successfully played.
+func Score(piles map[Color]int) int {
+	score := 0
+	for _, height := range piles {
+		score += height
+	}
+	return score
+}
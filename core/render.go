@@ -0,0 +1,107 @@
+package yanhuo
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Options for Board.Render. The zero value shows every card, without
+// colors.
+type RenderOptions struct {
+	// If set, this player's cards are shown as "??", as they would see them.
+	Hidden *PlayerIndex
+
+	// Whether to color cards with ANSI escape codes, for terminals.
+	Colors bool
+}
+
+var kANSIColors = map[Color]string{
+	WHITE:  "\x1b[1;37m",
+	RED:    "\x1b[1;31m",
+	BLUE:   "\x1b[1;34m",
+	YELLOW: "\x1b[1;33m",
+	GREEN:  "\x1b[1;32m",
+}
+
+const kANSIReset = "\x1b[0m"
+
+// Returns the board as a block of fixed-width text, e.g.
+//
+//	Piles: W0 R2 B1 Y0 G3   Blue: 5/8  Red: 2/3  Deck: 23
+//	Player 0: R1 B3 G2 W4
+//	          R? ?? ?2 ??
+//	Player 1: ...
+//	Discards: R1 R1 | B4 | G2
+//
+// Under each player's cards is what they have been told about them.
+func (b Board) Render(options RenderOptions) string {
+	var buffer bytes.Buffer
+
+	colored := func(c Color, s string) string {
+		if !options.Colors {
+			return s
+		}
+		return kANSIColors[c] + s + kANSIReset
+	}
+
+	buffer.WriteString("Piles:")
+	for _, c := range ALL_COLORS {
+		buffer.WriteString(" " + colored(c, fmt.Sprintf("%s%d", kColorInfos[c].shortName, b.Piles[c])))
+	}
+	buffer.WriteString(fmt.Sprintf("   Blue: %d/%d  Red: %d/%d  Deck: %d\n",
+		b.BlueTokens, b.Rules.MaxBlueTokens, b.RedTokens, b.Rules.RedTokens, b.DeckSize))
+
+	for p, hand := range b.Hands {
+		label := fmt.Sprintf("Player %d: ", p)
+		hidden := options.Hidden != nil && *options.Hidden == PlayerIndex(p)
+
+		buffer.WriteString(label)
+		for i, c := range hand {
+			if i > 0 {
+				buffer.WriteString(" ")
+			}
+			if hidden || c.IsUnknown() {
+				buffer.WriteString("??")
+			} else {
+				buffer.WriteString(colored(c.Color, c.String()))
+			}
+		}
+		buffer.WriteString("\n")
+
+		if p >= len(b.Knowledge) {
+			continue
+		}
+		buffer.WriteString(fmt.Sprintf("%*s", len(label), ""))
+		for i, k := range b.Knowledge[p] {
+			if i > 0 {
+				buffer.WriteString(" ")
+			}
+			if c, ok := k.Color(); ok {
+				buffer.WriteString(colored(c, k.String()))
+			} else {
+				buffer.WriteString(k.String())
+			}
+		}
+		buffer.WriteString("\n")
+	}
+
+	buffer.WriteString("Discards:")
+	sep := " "
+	for _, c := range ALL_COLORS {
+		group := ""
+		for _, v := range ALL_VALUES {
+			for _, d := range b.Discards {
+				if d.Color == c && d.Value == v {
+					group += " " + colored(c, d.String())
+				}
+			}
+		}
+		if group != "" {
+			buffer.WriteString(sep + group[1:])
+			sep = " | "
+		}
+	}
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
@@ -0,0 +1,93 @@
+package yanhuo
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func testBoard() Board {
+	tracker := NewBoardTracker()
+	tracker.GameStart([][]Card{
+		{{Color: RED, Value: 1}, {Color: BLUE, Value: 3}},
+		{{Color: RED, Value: 2}, {Color: GREEN, Value: 1}},
+	})
+	tracker.ObserveAction(0, Action{GiveInformation: NewValueHint(1, tracker.board.Hands[1], 1)})
+	tracker.TurnComplete(map[Color]int{}, 7, 3)
+	tracker.ObserveAction(1, Action{Discard: &DiscardAction{Index: 0}})
+	tracker.ObserveDiscard(1, Card{Color: RED, Value: 2}, 0)
+	tracker.ObserveDraw(1, Card{Color: WHITE, Value: 4}, 0)
+	tracker.TurnComplete(map[Color]int{}, 8, 3)
+	return tracker.Board()
+}
+
+func TestRender(t *testing.T) {
+	expected := strings.Join([]string{
+		"Piles: W0 R0 B0 Y0 G0   Blue: 8/8  Red: 3/3  Deck: 45",
+		"Player 0: R1 B3",
+		"          ?? ??",
+		"Player 1: W4 G1",
+		"          ?? ?1",
+		"Discards: R2",
+		"",
+	}, "\n")
+
+	if actual := testBoard().Render(RenderOptions{}); actual != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, actual)
+	}
+}
+
+func TestRenderHidesPlayer(t *testing.T) {
+	hidden := PlayerIndex(1)
+	actual := testBoard().Render(RenderOptions{Hidden: &hidden, Colors: true})
+
+	if !strings.Contains(actual, "Player 1: ?? ??\n") {
+		t.Errorf("Player 1's cards should be hidden:\n%s", actual)
+	}
+	if !strings.Contains(actual, kANSIColors[RED]+"R1"+kANSIReset) {
+		t.Errorf("Player 0's cards should be colored:\n%q", actual)
+	}
+}
+
+func TestLoggingObserverShowsBoard(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}},
+		[]Observer{&LoggingObserver{ShowBoard: true}},
+		GameOptions{Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	if boards := strings.Count(out.String(), "Piles:"); boards != len(game.Record().Turns) {
+		t.Errorf("Expected a board after each of %d turns, got %d", len(game.Record().Turns), boards)
+	}
+}
+
+func TestLoggingObserverShowsRules(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	rules := StandardRules()
+	rules.MaxBlueTokens = 6
+	rules.Suits = map[Color]SuitRules{RED: {ColorHints: TouchAlways}}
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}},
+		[]Observer{&LoggingObserver{ShowBoard: true}},
+		GameOptions{Seed: 5, Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	if !strings.Contains(out.String(), "Blue: 6/6") || strings.Contains(out.String(), "/8") {
+		t.Errorf("Expected the board to show the game's rules:\n%s", out.String())
+	}
+}
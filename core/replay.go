@@ -0,0 +1,86 @@
+package yanhuo
+
+import (
+	"fmt"
+)
+
+// Plays back the actions in a recorded game, in turn. Turns are shared by
+// every seat, and counted from 1 in errors.
+type scriptedStrategy struct {
+	turns []TurnRecord
+	next  *int
+}
+
+func (s *scriptedStrategy) StartGame(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) {
+}
+
+func (s *scriptedStrategy) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	*s.next++
+	if *s.next > len(s.turns) {
+		panic(fmt.Sprintf("Game still in progress after all %d recorded turns", len(s.turns)))
+	}
+	turn := s.turns[*s.next-1]
+	if turn.Player != me {
+		panic(fmt.Sprintf("Recorded for player %d, but it is player %d's turn", turn.Player, me))
+	}
+	return turn.Action
+}
+
+func (s *scriptedStrategy) ObserveAction(actor PlayerIndex, action Action) {
+}
+
+// Plays a recorded game again, sending every event to observers exactly as
+// they were sent during the original game. Returns an error if the record
+// doesn't describe a legal, complete game.
+func Replay(record *GameRecord, observers []Observer) (err error) {
+	next := 0
+	players := make([]PlayerStrategy, record.NumPlayers)
+	for i := range players {
+		players[i] = &scriptedStrategy{turns: record.Turns, next: &next}
+	}
+
+	options, err := record.options()
+	if err != nil {
+		return err
+	}
+
+	game, err := InitializeGameWithOptions(players, observers, options)
+	if err != nil {
+		return err
+	}
+	game.currentPlayer = record.StartingPlayer
+	game.record.StartingPlayer = record.StartingPlayer
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Invalid record at turn %d: %v", next, r)
+		}
+	}()
+
+	game.Play()
+
+	if next != len(record.Turns) {
+		return fmt.Errorf("Game ended after %d of %d recorded turns", next, len(record.Turns))
+	}
+	return nil
+}
+
+// Returns the options to set up the game described by record, or an error if
+// the record can't describe a legal game.
+func (record *GameRecord) options() (GameOptions, error) {
+	if len(record.Deck) != len(NewDeck()) {
+		return GameOptions{}, fmt.Errorf("Recorded deck has %d cards, expected %d", len(record.Deck), len(NewDeck()))
+	}
+	if record.StartingPlayer < 0 || int(record.StartingPlayer) >= record.NumPlayers {
+		return GameOptions{}, fmt.Errorf("Invalid starting player: %d", record.StartingPlayer)
+	}
+
+	options := GameOptions{
+		Seed:          record.Seed,
+		Deck:          record.Deck,
+		AllowCheating: record.Cheating,
+	}
+	rules := record.PlayedRules()
+	options.Rules = &rules
+	return options, nil
+}
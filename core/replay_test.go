@@ -0,0 +1,140 @@
+package yanhuo
+
+import (
+	"testing"
+)
+
+func TestReplayReproducesGame(t *testing.T) {
+	original := NewBoardTracker()
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}, &firstCardDiscarder{}},
+		[]Observer{original},
+		GameOptions{Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	replayed := NewBoardTracker()
+	if err := Replay(record, []Observer{replayed}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(replayed.Boards) != len(original.Boards) {
+		t.Fatalf("Replay had %d boards, original had %d", len(replayed.Boards), len(original.Boards))
+	}
+	for i := range original.Boards {
+		if a, b := original.Boards[i].Render(RenderOptions{}), replayed.Boards[i].Render(RenderOptions{}); a != b {
+			t.Errorf("Boards differ after turn %d. Original:\n%s\nReplayed:\n%s", i, a, b)
+		}
+	}
+
+	final := replayed.Boards[len(replayed.Boards)-1]
+	if Score(final.Piles) != record.Score {
+		t.Errorf("Final board scores %d, record says %d", Score(final.Piles), record.Score)
+	}
+	if len(final.Discards) == 0 || final.DeckSize != 0 {
+		t.Errorf("Discarders should have run the deck out: %d discards, %d cards left",
+			len(final.Discards), final.DeckSize)
+	}
+}
+
+func TestReplayRejectsBadRecords(t *testing.T) {
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}},
+		nil,
+		GameOptions{Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	truncated := *record
+	truncated.Turns = record.Turns[:1]
+	if err := Replay(&truncated, nil); err == nil {
+		t.Errorf("Expected an error replaying a truncated game")
+	}
+
+	wrongPlayer := *record
+	wrongPlayer.StartingPlayer = 1 - record.StartingPlayer
+	if err := Replay(&wrongPlayer, nil); err == nil {
+		t.Errorf("Expected an error replaying turns out of order")
+	}
+
+	// only zero rules mean the standard ones
+	noBlueTokens := *record
+	noBlueTokens.Rules.MaxBlueTokens = 0
+	if err := Replay(&noBlueTokens, nil); err == nil {
+		t.Errorf("Expected an error replaying a game without blue tokens")
+	}
+}
+
+func TestReplayLegacyRecord(t *testing.T) {
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}},
+		nil,
+		GameOptions{Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	// records made before rules were recorded have none
+	record := game.Record()
+	record.Rules = Rules{}
+	if err := Replay(record, nil); err != nil {
+		t.Errorf("Expected a record without rules to replay by the standard rules: %v", err)
+	}
+	if rules := record.PlayedRules(); rules.MaxBlueTokens != 8 || rules.RedTokens != 3 {
+		t.Errorf("Expected the standard rules, got %+v", rules)
+	}
+}
+
+func TestBoardTrackerKnowledge(t *testing.T) {
+	tracker := NewBoardTracker()
+	tracker.GameStart([][]Card{
+		{{Color: RED, Value: 1}, {Color: BLUE, Value: 3}},
+		{{Color: RED, Value: 2}, {Color: GREEN, Value: 1}},
+	})
+	tracker.ObserveAction(0, Action{GiveInformation: NewColorHint(1, tracker.board.Hands[1], RED)})
+	tracker.TurnComplete(map[Color]int{}, 7, 3)
+
+	knowledge := tracker.Boards[1].Knowledge[1]
+	if knowledge[0].String() != "R?" || knowledge[1].String() != "??" {
+		t.Errorf("Unexpected knowledge after a red hint: %s, %s", knowledge[0], knowledge[1])
+	}
+	if tracker.Boards[0].Knowledge[1][0].Touched {
+		t.Errorf("Earlier boards should not change")
+	}
+}
+
+func TestBoardChop(t *testing.T) {
+	tracker := NewBoardTracker()
+	tracker.GameStart([][]Card{
+		{{Color: RED, Value: 1}, {Color: BLUE, Value: 3}, {Color: GREEN, Value: 5}},
+		{{Color: RED, Value: 2}, {Color: GREEN, Value: 1}, {Color: WHITE, Value: 4}},
+	})
+	if chop := tracker.Board().Chop(1); chop != 0 {
+		t.Errorf("Oldest card should be the chop, got %d", chop)
+	}
+
+	tracker.ObserveAction(0, Action{GiveInformation: NewValueHint(1, tracker.board.Hands[1], 2)})
+	tracker.TurnComplete(map[Color]int{}, 7, 3)
+	if chop := tracker.Board().Chop(1); chop != 1 {
+		t.Errorf("Touched cards can't be the chop, got %d", chop)
+	}
+
+	tracker.ObserveAction(1, Action{Discard: &DiscardAction{Index: 1}})
+	tracker.ObserveDiscard(1, Card{Color: GREEN, Value: 1}, 1)
+	tracker.ObserveDraw(1, Card{Color: YELLOW, Value: 1}, 1)
+	tracker.TurnComplete(map[Color]int{}, 8, 3)
+	board := tracker.Board()
+	if chop := board.Chop(1); chop != 2 {
+		t.Errorf("Newly drawn card shouldn't be the chop, got %d", chop)
+	}
+	if !board.IsCritical(Card{Color: GREEN, Value: 5}) || board.IsCritical(Card{Color: GREEN, Value: 1}) {
+		t.Errorf("Only the 5 should be critical")
+	}
+}
@@ -0,0 +1,206 @@
+package yanhuo
+
+import (
+	"fmt"
+)
+
+// Rules configures how a game is played. Start from StandardRules and
+// change the options wanted.
+type Rules struct {
+	// The most blue (information) tokens the players can hold. They start
+	// with this many.
+	MaxBlueTokens int
+
+	// The number of red tokens the players start with. A misplay loses one,
+	// and losing the last one ends the game.
+	RedTokens int
+
+	// The number of cards dealt to each player, by number of players. Games
+	// can only be played with the numbers of players listed.
+	HandSizes map[int]int
+
+	// Whether successfully playing a 5, completing its suit, gives back a
+	// blue token (if the players don't already hold them all).
+	RefundBlueTokenOnFive bool
+
+	// Whether a game which ends by losing every red token scores zero,
+	// rather than the number of cards played.
+	StrikeOutScoresZero bool
+
+	// Variant rules for which hints touch the cards of some suits. Suits
+	// not listed are touched by hints matching their color or value.
+	Suits map[Color]SuitRules `json:",omitempty"`
+}
+
+// Says which hints of one kind (color or value) touch a card.
+type Touch int8
+
+const (
+	// Touched by hints which match the card.
+	TouchMatching Touch = iota
+	// Touched by every hint, e.g. a "rainbow" suit for color hints, or a
+	// "pink" suit for value hints.
+	TouchAlways
+	// Touched by no hint, e.g. a "null" suit for color hints, or a "brown"
+	// suit for value hints.
+	TouchNever
+)
+
+// SuitRules says which hints touch the cards of a suit.
+type SuitRules struct {
+	ColorHints Touch
+	ValueHints Touch
+}
+
+// Returns the rules of the standard game, extended to 6 players with 3 cards
+// each.
+func StandardRules() Rules {
+	return Rules{
+		MaxBlueTokens: 8,
+		RedTokens:     3,
+		HandSizes: map[int]int{
+			2: 5,
+			3: 5,
+			4: 4,
+			5: 4,
+			6: 3,
+		},
+		RefundBlueTokenOnFive: true,
+		StrikeOutScoresZero:   false,
+	}
+}
+
+// Returns the number of cards dealt to each player in a game with
+// numPlayers players.
+func (r Rules) HandSize(numPlayers int) (int, error) {
+	cardsPerPlayer, ok := r.HandSizes[numPlayers]
+	if !ok {
+		return 0, fmt.Errorf("Invalid number of players: %d", numPlayers)
+	}
+	if cardsPerPlayer < 1 {
+		return 0, fmt.Errorf("Invalid hand size for %d players: %d", numPlayers, cardsPerPlayer)
+	}
+	return cardsPerPlayer, nil
+}
+
+// Returns an error if a game with numPlayers players can't be played by
+// these rules.
+func (r Rules) Validate(numPlayers int) error {
+	if r.MaxBlueTokens < 1 {
+		return fmt.Errorf("Invalid number of blue tokens: %d", r.MaxBlueTokens)
+	}
+	if r.RedTokens < 1 {
+		return fmt.Errorf("Invalid number of red tokens: %d", r.RedTokens)
+	}
+	_, err := r.HandSize(numPlayers)
+	return err
+}
+
+// Whether r is the zero value, rather than rules a game could be played
+// by. Records made before rules were recorded have zero Rules.
+func (r Rules) IsZero() bool {
+	return r.MaxBlueTokens == 0 && r.RedTokens == 0 && len(r.HandSizes) == 0 &&
+		!r.RefundBlueTokenOnFive && !r.StrikeOutScoresZero && len(r.Suits) == 0
+}
+
+// Returns the score for a game which ended with the given piles. struckOut
+// says whether it ended because the last red token was lost.
+func (r Rules) Score(piles map[Color]int, struckOut bool) int {
+	if struckOut && r.StrikeOutScoresZero {
+		return 0
+	}
+	return Score(piles)
+}
+
+// Returns a copy of the rules which shares nothing with r.
+func (r Rules) copy() Rules {
+	out := r
+	out.HandSizes = make(map[int]int, len(r.HandSizes))
+	for n, size := range r.HandSizes {
+		out.HandSizes[n] = size
+	}
+	if r.Suits != nil {
+		out.Suits = make(map[Color]SuitRules, len(r.Suits))
+		for c, suit := range r.Suits {
+			out.Suits[c] = suit
+		}
+	}
+	return out
+}
+
+//
+// Hints
+//
+
+// Whether hint touches card c.
+func (r Rules) Touches(hint *GiveInformationAction, c Card) bool {
+	suit := r.Suits[c.Color]
+	if hint.Color != nil {
+		switch suit.ColorHints {
+		case TouchAlways:
+			return true
+		case TouchNever:
+			return false
+		}
+		return c.Color == hint.Color.Color
+	}
+
+	switch suit.ValueHints {
+	case TouchAlways:
+		return true
+	case TouchNever:
+		return false
+	}
+	return c.Value == hint.Value.Value
+}
+
+// Whether players may give a hint naming color c. Only suits which color
+// hints touch normally can be named.
+func (r Rules) CanName(c Color) bool {
+	return r.Suits[c].ColorHints == TouchMatching
+}
+
+func (r Rules) hint(hint *GiveInformationAction, cards []Card) *GiveInformationAction {
+	hint.Cards = []HandIndex{}
+	for i, card := range cards {
+		if r.Touches(hint, card) {
+			hint.Cards = append(hint.Cards, HandIndex(i))
+		}
+	}
+	return hint
+}
+
+// Returns the information action telling player p about every card in cards
+// (that player's hand) touched by naming color c.
+func (r Rules) ColorHint(p PlayerIndex, cards []Card, c Color) *GiveInformationAction {
+	return r.hint(&GiveInformationAction{PlayerIndex: p, Color: &ColorInformation{Color: c}}, cards)
+}
+
+// Returns the information action telling player p about every card in cards
+// (that player's hand) touched by naming value v.
+func (r Rules) ValueHint(p PlayerIndex, cards []Card, v Value) *GiveInformationAction {
+	return r.hint(&GiveInformationAction{PlayerIndex: p, Value: &ValueInformation{Value: v}}, cards)
+}
+
+// Returns every hint which could be given to player p, holding cards, which
+// touches at least one card.
+func (r Rules) LegalHints(p PlayerIndex, cards []Card) []*GiveInformationAction {
+	hints := []*GiveInformationAction{}
+
+	for _, c := range ALL_COLORS {
+		if !r.CanName(c) {
+			continue
+		}
+		if hint := r.ColorHint(p, cards, c); len(hint.Cards) > 0 {
+			hints = append(hints, hint)
+		}
+	}
+
+	for _, v := range ALL_VALUES {
+		if hint := r.ValueHint(p, cards, v); len(hint.Cards) > 0 {
+			hints = append(hints, hint)
+		}
+	}
+
+	return hints
+}
@@ -0,0 +1,272 @@
+package yanhuo
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestRefundBlueTokenOnFive(t *testing.T) {
+	for _, refund := range []bool{true, false} {
+		rules := StandardRules()
+		rules.RefundBlueTokenOnFive = refund
+
+		deck := NewDeck()
+		deck[0], deck[len(deck)-1] = deck[len(deck)-1], deck[0] // G5 first
+		game, err := InitializeGameWithOptions(
+			[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}},
+			nil,
+			GameOptions{Seed: 1, Deck: deck, Rules: &rules})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		game.currentPlayer = 0
+		game.pileHeights[GREEN] = 4
+		game.blueTokens = 5
+		game.handlePlayAction(game.playerStates[0], &PlayAction{Index: 0})
+
+		expected := 5
+		if refund {
+			expected = 6
+		}
+		if game.pileHeights[GREEN] != 5 || game.blueTokens != expected {
+			t.Errorf("Refund %t: expected %d blue tokens, got %d", refund, expected, game.blueTokens)
+		}
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	rules := StandardRules()
+	rules.MaxBlueTokens = 4
+	rules.RedTokens = 1
+	rules.HandSizes = map[int]int{2: 3}
+	rules.StrikeOutScoresZero = true
+
+	if _, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}, &firstCardPlayer{}},
+		nil, GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Expected an error for a number of players the rules don't cover")
+	}
+
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}},
+		nil,
+		GameOptions{Seed: 8, Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(game.playerStates[0].cards) != 3 || game.blueTokens != 4 || game.redTokens != 1 {
+		t.Errorf("Game not set up by the rules: %d cards, %d blue, %d red",
+			len(game.playerStates[0].cards), game.blueTokens, game.redTokens)
+	}
+
+	// changing the options afterwards mustn't affect the game
+	rules.MaxBlueTokens = 100
+	game.Play()
+	record := game.Record()
+
+	if game.redTokens != 0 || record.Score != 0 {
+		t.Errorf("A struck out game should score 0, got %d", record.Score)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded GameRecord
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	r := decoded.Rules
+	if r.MaxBlueTokens != 4 || r.RedTokens != 1 || r.HandSizes[2] != 3 || !r.StrikeOutScoresZero || !r.RefundBlueTokenOnFive {
+		t.Errorf("Rules not recorded: %+v", r)
+	}
+	if err := Replay(&decoded, nil); err != nil {
+		t.Errorf("Couldn't replay with the recorded rules: %v", err)
+	}
+}
+
+func TestTableSizes(t *testing.T) {
+	players := func(n int) []PlayerStrategy {
+		out := []PlayerStrategy{}
+		for i := 0; i < n; i++ {
+			out = append(out, &firstCardDiscarder{})
+		}
+		return out
+	}
+
+	game, err := InitializeGame(players(6), nil)
+	if err != nil {
+		t.Fatalf("Standard rules should allow 6 players: %v", err)
+	}
+	if len(game.playerStates[5].cards) != 3 || len(game.drawPile) != 50-18 {
+		t.Errorf("Expected 6 hands of 3, got %d cards each and %d left",
+			len(game.playerStates[5].cards), len(game.drawPile))
+	}
+	game.Play()
+
+	rules := StandardRules()
+	rules.HandSizes = map[int]int{2: 6, 7: 8}
+	game, err = InitializeGameWithOptions(players(2), nil, GameOptions{Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(game.playerStates[0].cards) != 6 {
+		t.Errorf("Expected 6 cards each, got %d", len(game.playerStates[0].cards))
+	}
+	game.Play()
+
+	if _, err := InitializeGameWithOptions(players(7), nil, GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Expected an error dealing 56 cards from a deck of 50")
+	}
+	if _, err := InitializeGameWithOptions(players(2), nil, GameOptions{Rules: &rules, Deck: NewDeck()[:10]}); err == nil {
+		t.Errorf("Expected an error dealing 12 cards from a deck of 10")
+	}
+
+	rules.HandSizes = map[int]int{2: 0}
+	if _, err := InitializeGameWithOptions(players(2), nil, GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Expected an error for empty hands")
+	}
+}
+
+func TestTokenCounts(t *testing.T) {
+	players := []PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}}
+
+	rules := StandardRules()
+	rules.MaxBlueTokens = 0
+	if _, err := InitializeGameWithOptions(players, nil, GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Expected an error playing without blue tokens")
+	}
+
+	rules = StandardRules()
+	rules.RedTokens = 0
+	if _, err := InitializeGameWithOptions(players, nil, GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Expected an error playing without red tokens")
+	}
+}
+
+func variantRules() Rules {
+	rules := StandardRules()
+	rules.Suits = map[Color]SuitRules{
+		RED:    {ColorHints: TouchAlways}, // rainbow
+		BLUE:   {ColorHints: TouchNever},  // null
+		GREEN:  {ValueHints: TouchAlways}, // pink
+		YELLOW: {ValueHints: TouchNever},  // brown
+	}
+	return rules
+}
+
+func TestVariantSuits(t *testing.T) {
+	rules := variantRules()
+	hand := []Card{{Color: RED, Value: 3}, {Color: BLUE, Value: 2}, {Color: GREEN, Value: 4}, {Color: YELLOW, Value: 1}, {Color: WHITE, Value: 2}}
+
+	if rules.CanName(RED) || rules.CanName(BLUE) || !rules.CanName(GREEN) {
+		t.Errorf("Only suits touched by matching color hints should be nameable")
+	}
+
+	expectCards := func(hint *GiveInformationAction, expected string) {
+		if actual := fmt.Sprint(hint.Cards); actual != expected {
+			t.Errorf("Hint %s: expected cards %s, got %s", hint.DebugString(), expected, actual)
+		}
+	}
+	expectCards(rules.ColorHint(0, hand, WHITE), "[0 4]")
+	expectCards(rules.ColorHint(0, hand, YELLOW), "[0 3]")
+	expectCards(rules.ValueHint(0, hand, 2), "[1 2 4]")
+	expectCards(rules.ValueHint(0, hand, 1), "[2]")
+
+	// white, yellow and green each touch the rainbow card, and every value
+	// touches the pink card
+	if hints := rules.LegalHints(0, hand); len(hints) != 8 {
+		t.Errorf("Expected 8 legal hints, got %d", len(hints))
+	}
+
+	h := NewHandKnowledge(len(hand))
+	h.ApplyHintWithRules(rules.ColorHint(0, hand, WHITE), rules)
+	if c := h[0].PossibleColors(); fmt.Sprint(c) != fmt.Sprint([]Color{WHITE, RED}) {
+		t.Errorf("A card touched by a white hint could be white or rainbow, got %v", c)
+	}
+	if h[1].CanBeColor(WHITE) || h[1].CanBeColor(RED) || !h[1].CanBeColor(BLUE) {
+		t.Errorf("A card missed by a white hint is neither white nor rainbow: %s", h[1])
+	}
+
+	h.ApplyHintWithRules(rules.ValueHint(0, hand, 2), rules)
+	if h[3].CanBe(Card{Color: BLUE, Value: 2}) || !h[3].CanBe(Card{Color: YELLOW, Value: 2}) || h[3].CanBeColor(GREEN) {
+		t.Errorf("A card missed by a 2 hint can't be pink, or a 2 unless it's brown: %v", h[3].PossibleCards())
+	}
+}
+
+// Gives the first legal hint by the rules of the game, while it can.
+type variantHinter struct {
+	firstCardDiscarder
+	rules Rules
+}
+
+func (p *variantHinter) ObserveView(v *View) {
+	p.rules = v.Rules()
+}
+
+func (p *variantHinter) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	next := PlayerIndex((int(me) + 1) % (len(others) + 1))
+	if hints := p.rules.LegalHints(next, others[next]); blue > 0 && len(hints) > 0 {
+		return Action{GiveInformation: hints[len(hints)/2]}
+	}
+	return p.firstCardDiscarder.Act(me, others, numCards, blue, red)
+}
+
+func TestVariantGame(t *testing.T) {
+	rules := variantRules()
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&variantHinter{}, &variantHinter{}, &variantHinter{}},
+		nil,
+		GameOptions{Seed: 5, Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Naming a null suit should be rejected")
+			}
+		}()
+		cards := game.playerStates[1].cards
+		game.handleGiveInformationAction(rules.ColorHint(1, cards, BLUE))
+	}()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("A hint touching cards by the standard rules should be rejected")
+			}
+		}()
+		cards := []Card{{Color: GREEN, Value: 1}, {Color: GREEN, Value: 2}}
+		game.playerStates[1].cards = cards
+		game.handleGiveInformationAction(NewValueHint(1, cards, 1))
+	}()
+
+	game, err = InitializeGameWithOptions(
+		[]PlayerStrategy{&variantHinter{}, &variantHinter{}, &variantHinter{}},
+		nil,
+		GameOptions{Seed: 5, Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+	if record.Rules.Suits[YELLOW].ValueHints != TouchNever {
+		t.Errorf("Suit rules not recorded: %+v", record.Rules.Suits)
+	}
+
+	// the tracker should agree with the engine about what players know
+	tracker := NewBoardTracker()
+	if err := Replay(record, []Observer{tracker}); err != nil {
+		t.Fatal(err)
+	}
+	final := tracker.Boards[len(tracker.Boards)-1]
+	for p, h := range game.knowledge {
+		if fmt.Sprint(h) != fmt.Sprint(final.Knowledge[p]) {
+			t.Errorf("Player %d: engine knows %v, tracker knows %v", p, h, final.Knowledge[p])
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package yanhuo
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Counts of what one player did over one or more games.
+type PlayerStats struct {
+	Plays    int
+	Misplays int
+
+	Discards int
+	// Discards of the last remaining copy of a card which was still needed
+	// (including any 5 which hadn't been played).
+	CriticalDiscards int
+	// Discards made while holding every blue token, so no token was gained.
+	WastedBlueTokens int
+
+	ColorHints int
+	ValueHints int
+	// The total number of cards touched by this player's hints.
+	CardsTouched int
+	// Of those, the cards which no earlier hint had touched.
+	NewlyTouched int
+	// The total reduction in entropy of the recipients' hands, in bits (see
+	// HintEffect).
+	EntropyReduction float64
+}
+
+func (s PlayerStats) Hints() int {
+	return s.ColorHints + s.ValueHints
+}
+
+// Returns the average number of cards touched per hint given.
+func (s PlayerStats) CardsPerHint() float64 {
+	if s.Hints() == 0 {
+		return 0
+	}
+	return float64(s.CardsTouched) / float64(s.Hints())
+}
+
+// Returns the average number of cards newly touched per hint given: "cards
+// gotten per clue".
+func (s PlayerStats) Efficiency() float64 {
+	if s.Hints() == 0 {
+		return 0
+	}
+	return float64(s.NewlyTouched) / float64(s.Hints())
+}
+
+func (s *PlayerStats) add(other PlayerStats) {
+	s.Plays += other.Plays
+	s.Misplays += other.Misplays
+	s.Discards += other.Discards
+	s.CriticalDiscards += other.CriticalDiscards
+	s.WastedBlueTokens += other.WastedBlueTokens
+	s.ColorHints += other.ColorHints
+	s.ValueHints += other.ValueHints
+	s.CardsTouched += other.CardsTouched
+	s.NewlyTouched += other.NewlyTouched
+	s.EntropyReduction += other.EntropyReduction
+}
+
+type GameStats struct {
+	Score int
+	Won   bool
+	Turns int
+
+	// Indexed by PlayerIndex
+	Players []PlayerStats
+}
+
+// StatsObserver collects statistics about every game it observes. The same
+// observer can be passed to many games, as long as they are played one at a
+// time; use Merge to combine observers from games played concurrently.
+type StatsObserver struct {
+	Games []GameStats
+
+	current    *GameStats
+	view       *View
+	rules      Rules
+	table      TableTracker
+	blueTokens int
+}
+
+func NewStatsObserver() *StatsObserver {
+	return &StatsObserver{Games: []GameStats{}}
+}
+
+// Hint efficiency is measured using the knowledge tracked by the game, so is
+// only counted if the observer is given a View. Without one, the observer
+// assumes the standard rules.
+func (o *StatsObserver) ObserveView(v *View) {
+	o.view = v
+}
+
+func (o *StatsObserver) GameStart(cards [][]Card) {
+	o.current = &GameStats{Players: make([]PlayerStats, len(cards))}
+	o.table.Reset()
+	o.rules = StandardRules()
+	if o.view != nil {
+		o.rules = o.view.Rules()
+	}
+	o.blueTokens = o.rules.MaxBlueTokens
+}
+
+func (o *StatsObserver) ObserveAction(p PlayerIndex, a Action) {
+	if a.GiveInformation == nil {
+		return
+	}
+
+	stats := &o.current.Players[p]
+	if a.GiveInformation.Color != nil {
+		stats.ColorHints++
+	} else {
+		stats.ValueHints++
+	}
+	stats.CardsTouched += len(a.GiveInformation.Cards)
+
+	// we're told about the action before the hint is given
+	if o.view != nil {
+		effect := MeasureHintWithRules(
+			o.view.Knowledge(a.GiveInformation.PlayerIndex), a.GiveInformation, o.rules)
+		stats.NewlyTouched += effect.NewlyTouched
+		stats.EntropyReduction += effect.EntropyReduction
+	}
+}
+
+func (o *StatsObserver) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	stats := &o.current.Players[p]
+	stats.Discards++
+	if o.table.IsCritical(c) {
+		stats.CriticalDiscards++
+	}
+	if o.blueTokens == o.rules.MaxBlueTokens {
+		stats.WastedBlueTokens++
+	}
+	o.table.ObserveDiscard(p, c, i)
+}
+
+func (o *StatsObserver) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+}
+
+func (o *StatsObserver) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	stats := &o.current.Players[p]
+	if successful {
+		stats.Plays++
+	} else {
+		stats.Misplays++
+	}
+	o.table.ObservePlay(p, c, successful)
+}
+
+func (o *StatsObserver) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	o.current.Turns++
+	o.blueTokens = blueTokens
+}
+
+func (o *StatsObserver) GameComplete(won bool, piles map[Color]int) {
+	o.current.Won = won
+	o.current.Score = Score(piles)
+	if o.view != nil {
+		o.current.Score = o.view.Score()
+	}
+	o.Games = append(o.Games, *o.current)
+	o.current = nil
+}
+
+// Adds the games observed by other to this observer's.
+func (o *StatsObserver) Merge(other *StatsObserver) {
+	o.Games = append(o.Games, other.Games...)
+}
+
+// Returns the stats for each seat, summed over every game.
+func (o *StatsObserver) Totals() []PlayerStats {
+	totals := []PlayerStats{}
+	for _, game := range o.Games {
+		for p, stats := range game.Players {
+			if p >= len(totals) {
+				totals = append(totals, PlayerStats{})
+			}
+			totals[p].add(stats)
+		}
+	}
+	return totals
+}
+
+func (o *StatsObserver) MeanScore() float64 {
+	if len(o.Games) == 0 {
+		return 0
+	}
+	total := 0
+	for _, game := range o.Games {
+		total += game.Score
+	}
+	return float64(total) / float64(len(o.Games))
+}
+
+func (o *StatsObserver) Wins() int {
+	wins := 0
+	for _, game := range o.Games {
+		if game.Won {
+			wins++
+		}
+	}
+	return wins
+}
+
+// Returns a table summarizing every game, suitable for a report.
+func (o *StatsObserver) Summary() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString(fmt.Sprintf("Games: %d, won: %d, mean score: %.2f\n",
+		len(o.Games), o.Wins(), o.MeanScore()))
+	buffer.WriteString("Player  Plays  Misplays  Discards  Critical  Wasted  Hints (C/V)  Cards/hint  New/hint  Bits/hint\n")
+	for p, stats := range o.Totals() {
+		bitsPerHint := 0.0
+		if stats.Hints() > 0 {
+			bitsPerHint = stats.EntropyReduction / float64(stats.Hints())
+		}
+		buffer.WriteString(fmt.Sprintf("%6d  %5d  %8d  %8d  %8d  %6d  %5d (%d/%d)  %10.2f  %8.2f  %9.2f\n",
+			p, stats.Plays, stats.Misplays, stats.Discards, stats.CriticalDiscards,
+			stats.WastedBlueTokens, stats.Hints(), stats.ColorHints, stats.ValueHints,
+			stats.CardsPerHint(), stats.Efficiency(), bitsPerHint))
+	}
+
+	return buffer.String()
+}
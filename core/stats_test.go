@@ -0,0 +1,114 @@
+package yanhuo
+
+import (
+	"testing"
+)
+
+func TestStatsObserver(t *testing.T) {
+	o := NewStatsObserver()
+	o.GameStart(make([][]Card, 2))
+
+	// Player 0 discards with every blue token in hand, then discards the
+	// only R5.
+	o.ObserveAction(0, Action{Discard: &DiscardAction{Index: 0}})
+	o.ObserveDiscard(0, Card{Color: RED, Value: 1}, 0)
+	o.TurnComplete(map[Color]int{}, 8, 3)
+
+	o.ObserveAction(1, Action{GiveInformation: &GiveInformationAction{
+		PlayerIndex: 0, Cards: []HandIndex{0, 2}, Value: &ValueInformation{Value: 1}}})
+	o.TurnComplete(map[Color]int{}, 8-1, 3)
+
+	o.ObserveAction(0, Action{Discard: &DiscardAction{Index: 1}})
+	o.ObserveDiscard(0, Card{Color: RED, Value: 5}, 1)
+	o.TurnComplete(map[Color]int{}, 8, 3)
+
+	o.ObserveAction(1, Action{Play: &PlayAction{Index: 0}})
+	o.ObservePlay(1, Card{Color: BLUE, Value: 1}, true)
+	o.TurnComplete(map[Color]int{BLUE: 1}, 8, 3)
+
+	o.ObserveAction(0, Action{Play: &PlayAction{Index: 0}})
+	o.ObservePlay(0, Card{Color: BLUE, Value: 3}, false)
+	o.TurnComplete(map[Color]int{BLUE: 1}, 8, 2)
+
+	o.GameComplete(false, map[Color]int{BLUE: 1})
+
+	other := NewStatsObserver()
+	other.GameStart(make([][]Card, 2))
+	other.ObserveAction(1, Action{GiveInformation: &GiveInformationAction{
+		PlayerIndex: 0, Cards: []HandIndex{3}, Color: &ColorInformation{Color: RED}}})
+	other.TurnComplete(map[Color]int{}, 8-1, 3)
+	other.GameComplete(true, map[Color]int{RED: 5, BLUE: 5, WHITE: 5, GREEN: 5, YELLOW: 5})
+	o.Merge(other)
+
+	if len(o.Games) != 2 || o.Wins() != 1 || o.MeanScore() != 13 {
+		t.Errorf("Wrong game totals: %d games, %d wins, mean score %f", len(o.Games), o.Wins(), o.MeanScore())
+	}
+
+	totals := o.Totals()
+	p0, p1 := totals[0], totals[1]
+	if p0.Discards != 2 || p0.CriticalDiscards != 1 || p0.WastedBlueTokens != 1 || p0.Misplays != 1 {
+		t.Errorf("Wrong stats for player 0: %+v", p0)
+	}
+	if p1.Plays != 1 || p1.Hints() != 2 || p1.ValueHints != 1 || p1.CardsPerHint() != 1.5 {
+		t.Errorf("Wrong stats for player 1: %+v", p1)
+	}
+}
+
+// Hints the next player about the value of their first card, while there are
+// blue tokens, then discards.
+type firstCardHinter struct {
+	firstCardDiscarder
+}
+
+func (p *firstCardHinter) Act(me PlayerIndex, others map[PlayerIndex][]Card, numCards int, blue int, red int) Action {
+	next := PlayerIndex((int(me) + 1) % (len(others) + 1))
+	if blue > 0 && len(others[next]) > 0 {
+		return Action{GiveInformation: NewValueHint(next, others[next], others[next][0].Value)}
+	}
+	return p.firstCardDiscarder.Act(me, others, numCards, blue, red)
+}
+
+func TestMeasureHint(t *testing.T) {
+	cards := []Card{{Color: RED, Value: 1}, {Color: BLUE, Value: 1}, {Color: RED, Value: 3}}
+	h := NewHandKnowledge(3)
+
+	effect := MeasureHint(h, NewColorHint(0, cards, RED))
+	if effect.NewlyTouched != 2 || effect.EntropyReduction <= 0 {
+		t.Errorf("Unexpected effect of first hint: %+v", effect)
+	}
+	if h[0].Touched {
+		t.Errorf("MeasureHint shouldn't change the knowledge")
+	}
+
+	h.ApplyHint(NewColorHint(0, cards, RED))
+	if effect := MeasureHint(h, NewColorHint(0, cards, RED)); effect.NewlyTouched != 0 || effect.EntropyReduction != 0 {
+		t.Errorf("Repeating a hint should tell nothing new: %+v", effect)
+	}
+	if effect := MeasureHint(h, NewValueHint(0, cards, 1)); effect.NewlyTouched != 1 || effect.EntropyReduction <= 0 {
+		t.Errorf("Unexpected effect of value hint: %+v", effect)
+	}
+}
+
+func TestStatsObserverHintEfficiency(t *testing.T) {
+	o := NewStatsObserver()
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardHinter{}, &firstCardHinter{}},
+		[]Observer{o},
+		GameOptions{Seed: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	totals := o.Totals()
+	stats := totals[0]
+	stats.add(totals[1])
+	if stats.Hints() == 0 || stats.Efficiency() != record.HintEfficiency() {
+		t.Errorf("Stats efficiency %f (over %d hints) doesn't match the record's %f",
+			stats.Efficiency(), stats.Hints(), record.HintEfficiency())
+	}
+	if stats.NewlyTouched >= stats.CardsTouched {
+		t.Errorf("Repeated hints should touch some cards again: %+v", stats)
+	}
+}
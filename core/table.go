@@ -0,0 +1,106 @@
+package yanhuo
+
+// TableTracker keeps track of what one player can see of the table: the
+// other players' hands, the piles, and the discards. A strategy keeps one,
+// passes it what Act and Rejoin show it, forwards its TableObserver calls to
+// it, and calls Replace when another player's play or discard is resolved.
+type TableTracker struct {
+	// The cards in each other player's hand.
+	Hands map[PlayerIndex][]Card
+	Piles map[Color]int
+	// How many copies of each card have been discarded or misplayed.
+	Discards map[Card]int
+
+	// The card another player just drew, which we learn about before we
+	// learn which of their cards it replaced.
+	pendingDraw *Card
+}
+
+func NewTableTracker() *TableTracker {
+	t := &TableTracker{}
+	t.Reset()
+	return t
+}
+
+// Forgets everything seen, for the start of a new game.
+func (t *TableTracker) Reset() {
+	t.Hands = make(map[PlayerIndex][]Card)
+	t.Piles = make(map[Color]int)
+	t.Discards = make(map[Card]int)
+	t.pendingDraw = nil
+}
+
+// Records the other players' hands, as shown to StartGame, Act or Rejoin.
+func (t *TableTracker) UpdateHands(otherPlayersCards map[PlayerIndex][]Card) {
+	for p, cards := range otherPlayersCards {
+		t.Hands[p] = append([]Card{}, cards...)
+	}
+}
+
+func (t *TableTracker) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	t.Discards[c]++
+}
+
+func (t *TableTracker) ObserveDraw(p PlayerIndex, c Card, i HandIndex) {
+	t.pendingDraw = &c
+}
+
+func (t *TableTracker) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	if successful {
+		t.Piles[c.Color]++
+	} else {
+		t.Discards[c]++
+	}
+}
+
+// Called once another player's play or discard of the card at index i has
+// been resolved: the card is replaced by the one they drew, or removed if
+// the draw pile was empty. Returns whether they drew a card.
+func (t *TableTracker) Replace(p PlayerIndex, i HandIndex) bool {
+	drew := t.pendingDraw != nil
+	hand := t.Hands[p]
+	if int(i) < len(hand) {
+		if drew {
+			hand[i] = *t.pendingDraw
+		} else {
+			t.Hands[p] = append(hand[:i], hand[i+1:]...)
+		}
+	}
+	t.pendingDraw = nil
+	return drew
+}
+
+// Whether c could be played now.
+func (t *TableTracker) IsPlayable(c Card) bool {
+	return int(c.Value) == t.Piles[c.Color]+1
+}
+
+// Whether c has already been played, or can never be played because every
+// copy of a lower card in its color has been discarded.
+func (t *TableTracker) IsDead(c Card) bool {
+	return isDead(t.Piles, t.Discards, c)
+}
+
+// Whether c is still needed and is the last copy not yet discarded.
+func (t *TableTracker) IsCritical(c Card) bool {
+	return isCritical(t.Piles, t.Discards, c)
+}
+
+// Whether c has already been played, or can never be played because every
+// copy of a lower card in its color has been discarded.
+func isDead(piles map[Color]int, discards map[Card]int, c Card) bool {
+	if int(c.Value) <= piles[c.Color] {
+		return true
+	}
+	for v := Value(piles[c.Color] + 1); v < c.Value; v++ {
+		if discards[Card{Color: c.Color, Value: v}] == NumCopies(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether c is still needed and is the last copy not yet discarded.
+func isCritical(piles map[Color]int, discards map[Card]int, c Card) bool {
+	return !isDead(piles, discards, c) && discards[c] == NumCopies(c.Value)-1
+}
@@ -0,0 +1,64 @@
+package yanhuo
+
+import (
+	"testing"
+)
+
+func TestTableTrackerHands(t *testing.T) {
+	tracker := NewTableTracker()
+	tracker.UpdateHands(map[PlayerIndex][]Card{
+		1: {{Color: RED, Value: 1}, {Color: BLUE, Value: 2}, {Color: GREEN, Value: 3}},
+	})
+
+	tracker.ObservePlay(1, Card{Color: RED, Value: 1}, true)
+	tracker.ObserveDraw(1, Card{Color: WHITE, Value: 4}, 0)
+	if !tracker.Replace(1, 0) {
+		t.Errorf("Player 1 drew a card")
+	}
+	expected := "W4, B2, G3"
+	if s := summarizeCards(tracker.Hands[1]); s != expected {
+		t.Errorf("Expected the drawn card to replace the played one: %s, expected %s", s, expected)
+	}
+
+	// the draw pile is empty
+	tracker.ObserveDiscard(1, Card{Color: BLUE, Value: 2}, 1)
+	if tracker.Replace(1, 1) {
+		t.Errorf("Player 1 didn't draw a card")
+	}
+	expected = "W4, G3"
+	if s := summarizeCards(tracker.Hands[1]); s != expected {
+		t.Errorf("Expected the discarded card to be removed: %s, expected %s", s, expected)
+	}
+
+	if tracker.Piles[RED] != 1 || tracker.Discards[Card{Color: BLUE, Value: 2}] != 1 {
+		t.Errorf("Unexpected piles %v and discards %v", tracker.Piles, tracker.Discards)
+	}
+}
+
+func TestTableTrackerCardProperties(t *testing.T) {
+	tracker := NewTableTracker()
+	tracker.ObservePlay(0, Card{Color: RED, Value: 1}, true)
+	tracker.ObserveDiscard(0, Card{Color: BLUE, Value: 3}, 0)
+	tracker.ObserveDiscard(0, Card{Color: BLUE, Value: 3}, 0)
+	tracker.ObserveDiscard(0, Card{Color: GREEN, Value: 2}, 0)
+
+	for _, test := range []struct {
+		c        Card
+		playable bool
+		dead     bool
+		critical bool
+	}{
+		{Card{Color: RED, Value: 1}, false, true, false},
+		{Card{Color: RED, Value: 2}, true, false, false},
+		{Card{Color: BLUE, Value: 4}, false, true, false},
+		{Card{Color: GREEN, Value: 2}, false, false, true},
+		{Card{Color: WHITE, Value: 5}, false, false, true},
+	} {
+		if tracker.IsPlayable(test.c) != test.playable ||
+			tracker.IsDead(test.c) != test.dead ||
+			tracker.IsCritical(test.c) != test.critical {
+			t.Errorf("%v: expected playable %t, dead %t, critical %t",
+				test.c, test.playable, test.dead, test.critical)
+		}
+	}
+}
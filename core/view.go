@@ -0,0 +1,147 @@
+package yanhuo
+
+// View is a read-only view of the public state of a game: the piles, the
+// discard pile and the tokens, along with what can be worked out from them.
+// It always reflects the current state of the game, so it must only be used
+// from the goroutine playing the game (i.e. from within the callbacks of a
+// PlayerStrategy or Observer).
+type View struct {
+	game *gameState
+}
+
+// Returns a View of this game.
+func (game *gameState) View() *View {
+	return &View{game: game}
+}
+
+func (v *View) Rules() Rules {
+	return v.game.rules.copy()
+}
+
+// Returns the score so far, or the final score once the game is over.
+func (v *View) Score() int {
+	return v.game.rules.Score(v.game.pileHeights, v.game.redTokens == 0)
+}
+
+func (v *View) Piles() map[Color]int {
+	return copyPiles(v.game.pileHeights)
+}
+
+// Returns every card discarded or misplayed so far, in order.
+func (v *View) Discards() []Card {
+	return append([]Card{}, v.game.discards...)
+}
+
+func (v *View) DeckSize() int {
+	return len(v.game.drawPile)
+}
+
+func (v *View) BlueTokens() int {
+	return v.game.blueTokens
+}
+
+func (v *View) RedTokens() int {
+	return v.game.redTokens
+}
+
+// Returns what player p has been told about their hand by hints. Everyone
+// hears every hint, so this is public.
+func (v *View) Knowledge(p PlayerIndex) HandKnowledge {
+	return append(HandKnowledge{}, v.game.knowledge[p]...)
+}
+
+func (v *View) discardCounts() map[Card]int {
+	counts := map[Card]int{}
+	for _, c := range v.game.discards {
+		counts[c]++
+	}
+	return counts
+}
+
+// Returns the number of copies of c which haven't been discarded: those
+// played, in hands or still in the deck.
+func (v *View) Remaining(c Card) int {
+	return NumCopies(c.Value) - v.discardCounts()[c]
+}
+
+// Whether c has already been played, or can never be played because every
+// copy of a lower card in its color has been discarded.
+func (v *View) IsDead(c Card) bool {
+	return isDead(v.game.pileHeights, v.discardCounts(), c)
+}
+
+// Whether c is still needed and is the last copy not yet discarded.
+func (v *View) IsCritical(c Card) bool {
+	return isCritical(v.game.pileHeights, v.discardCounts(), c)
+}
+
+// Returns every card which is still needed and has only one copy left.
+func (v *View) CriticalCards() []Card {
+	discards := v.discardCounts()
+	critical := []Card{}
+	for _, color := range ALL_COLORS {
+		for _, value := range ALL_VALUES {
+			c := Card{Color: color, Value: value}
+			if isCritical(v.game.pileHeights, discards, c) {
+				critical = append(critical, c)
+			}
+		}
+	}
+	return critical
+}
+
+// Returns the highest value the pile of color c can still reach. Every card
+// above it is dead.
+func (v *View) MaxPileHeight(c Color) int {
+	return maxPileHeight(v.discardCounts(), c)
+}
+
+// Returns the highest score still possible, given the cards discarded.
+func (v *View) MaxScore() int {
+	return maxScore(v.discardCounts())
+}
+
+// Returns the game's pace: the number of cards which can still be
+// discarded while leaving enough turns to reach MaxScore. This is the
+// current score, plus the cards left in the deck, plus the number of players
+// (who each get one more turn once the deck runs out), minus MaxScore.
+func (v *View) Pace() int {
+	return pace(v.game.pileHeights, len(v.game.drawPile), len(v.game.playerStates), v.MaxScore())
+}
+
+// Returns how many more cards can be discarded before the pace becomes
+// critical, after which every discard lowers the best possible score.
+func (v *View) DiscardsLeft() int {
+	if p := v.Pace(); p > 0 {
+		return p
+	}
+	return 0
+}
+
+// Whether every suit can still be completed in the turns remaining.
+func (v *View) Winnable() bool {
+	return v.MaxScore() == len(ALL_COLORS)*len(ALL_VALUES) && v.Pace() >= 0
+}
+
+func pace(piles map[Color]int, deckSize int, numPlayers int, maxScore int) int {
+	return Score(piles) + deckSize + numPlayers - maxScore
+}
+
+// Returns the highest value the pile of color c can reach, once the given
+// cards have been discarded.
+func maxPileHeight(discards map[Card]int, c Color) int {
+	for _, v := range ALL_VALUES {
+		if discards[Card{Color: c, Value: v}] == NumCopies(v) {
+			return int(v) - 1
+		}
+	}
+	return len(ALL_VALUES)
+}
+
+func maxScore(discards map[Card]int) int {
+	score := 0
+	for _, c := range ALL_COLORS {
+		score += maxPileHeight(discards, c)
+	}
+	return score
+}
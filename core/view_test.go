@@ -0,0 +1,126 @@
+package yanhuo
+
+import (
+	"testing"
+)
+
+// Checks the View after every turn against the events observed.
+type viewChecker struct {
+	BaseObserver
+	t        *testing.T
+	view     *View
+	discards []Card
+	piles    map[Color]int
+}
+
+func (o *viewChecker) ObserveView(v *View) {
+	o.view = v
+}
+
+func (o *viewChecker) GameStart(cards [][]Card) {
+	o.piles = map[Color]int{}
+	if o.view == nil {
+		o.t.Fatalf("Observer should be given a View before the game starts")
+	}
+}
+
+func (o *viewChecker) ObserveDiscard(p PlayerIndex, c Card, i HandIndex) {
+	o.discards = append(o.discards, c)
+}
+
+func (o *viewChecker) ObservePlay(p PlayerIndex, c Card, successful bool) {
+	if successful {
+		o.piles[c.Color]++
+	} else {
+		o.discards = append(o.discards, c)
+	}
+}
+
+func (o *viewChecker) TurnComplete(piles map[Color]int, blueTokens int, redTokens int) {
+	if summarizeCards(o.view.Discards()) != summarizeCards(o.discards) {
+		o.t.Errorf("View discards: %s, observed: %s",
+			summarizeCards(o.view.Discards()), summarizeCards(o.discards))
+	}
+	if o.view.BlueTokens() != blueTokens || o.view.RedTokens() != redTokens {
+		o.t.Errorf("View tokens out of date")
+	}
+}
+
+func TestViewTracksDiscards(t *testing.T) {
+	checker := &viewChecker{t: t}
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardPlayer{}},
+		[]Observer{checker},
+		GameOptions{Seed: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	if len(checker.discards) == 0 {
+		t.Fatalf("Expected some discards")
+	}
+}
+
+func TestViewQueries(t *testing.T) {
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardPlayer{}, &firstCardPlayer{}},
+		nil,
+		GameOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := game.View()
+
+	if v.MaxScore() != 25 || len(v.CriticalCards()) != 5 {
+		t.Errorf("At the start, max score should be 25 (got %d), and only 5s critical (got %v)",
+			v.MaxScore(), v.CriticalCards())
+	}
+
+	game.discards = []Card{{Color: RED, Value: 2}, {Color: BLUE, Value: 1}, {Color: BLUE, Value: 1}}
+	if !v.IsCritical(Card{Color: RED, Value: 2}) || !v.IsCritical(Card{Color: BLUE, Value: 1}) {
+		t.Errorf("Last copies should be critical: %v", v.CriticalCards())
+	}
+	if v.Remaining(Card{Color: BLUE, Value: 1}) != 1 {
+		t.Errorf("Expected one B1 left, got %d", v.Remaining(Card{Color: BLUE, Value: 1}))
+	}
+
+	game.discards = append(game.discards, Card{Color: RED, Value: 2})
+	if v.MaxPileHeight(RED) != 1 || !v.IsDead(Card{Color: RED, Value: 3}) {
+		t.Errorf("Red should be dead above 1, max height %d", v.MaxPileHeight(RED))
+	}
+	if v.MaxScore() != 21 {
+		t.Errorf("Expected max score 21, got %d", v.MaxScore())
+	}
+}
+func TestPace(t *testing.T) {
+	game, err := InitializeGameWithOptions(
+		[]PlayerStrategy{&firstCardDiscarder{}, &firstCardDiscarder{}, &firstCardDiscarder{}},
+		nil,
+		GameOptions{Seed: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 50 cards, 15 dealt, 3 final turns, and nothing played yet
+	v := game.View()
+	if v.Pace() != 35+3-25 || v.DiscardsLeft() != v.Pace() || !v.Winnable() {
+		t.Errorf("Unexpected starting pace %d, discards left %d", v.Pace(), v.DiscardsLeft())
+	}
+
+	game.Play()
+	record := game.Record()
+	for i, turn := range record.Turns {
+		if turn.Pace > 13 || turn.MaxScore > 25 {
+			t.Fatalf("Turn %d: pace %d, max score %d", i, turn.Pace, turn.MaxScore)
+		}
+		// with nothing played, each discard takes one of the 35 cards from
+		// the deck
+		if i > 0 && i < 35 && turn.Pace+turn.MaxScore != record.Turns[i-1].Pace+record.Turns[i-1].MaxScore-1 {
+			t.Errorf("Turn %d: pace should fall by one, or rise as the max score falls", i)
+		}
+	}
+	if v.Winnable() || v.MaxScore() == 25 {
+		t.Errorf("A game of discards should end unwinnable, max score %d", v.MaxScore())
+	}
+}
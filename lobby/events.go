@@ -0,0 +1,182 @@
+package lobby
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// EventStream is an Observer which keeps every event in a game, as
+// JSONEvents, for spectators to follow while the game is played. Each event
+// is kept as everyone sees it, and as each player sees it (see
+// yanhuo.NewPerspectiveObserver), so that a spectator can be shown the game
+// from one seat without giving away that player's cards.
+//
+// It serves the events as Server-Sent Events (see ServeHTTP), and can be
+// used outside the lobby by passing it to yanhuo.InitializeGame.
+type EventStream struct {
+	yanhuo.MultiObserver
+
+	mu      sync.Mutex
+	logs    [][][]byte    // everyone's view, then each player's
+	changed chan struct{} // closed, and replaced, as events are added
+	closed  bool
+}
+
+// Returns an EventStream for a game with numPlayers players, tagging its
+// events with gameID.
+func NewEventStream(gameID string, numPlayers int) *EventStream {
+	s := &EventStream{
+		logs:    make([][][]byte, numPlayers+1),
+		changed: make(chan struct{}),
+	}
+	s.MultiObserver = append(s.MultiObserver, yanhuo.NewJSONLinesObserver(eventLog{s, 0}, gameID))
+	for p := 0; p < numPlayers; p++ {
+		o := yanhuo.NewJSONLinesObserver(eventLog{s, p + 1}, gameID)
+		s.MultiObserver = append(s.MultiObserver, yanhuo.NewPerspectiveObserver(yanhuo.PlayerIndex(p), o))
+	}
+	return s
+}
+
+// Collects the events written by one JSONLinesObserver, which writes one
+// event at a time.
+type eventLog struct {
+	stream *EventStream
+	index  int
+}
+
+func (l eventLog) Write(p []byte) (int, error) {
+	s := l.stream
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, fmt.Errorf("Event stream is closed")
+	}
+	// the encoder reuses p
+	s.logs[l.index] = append(s.logs[l.index], append([]byte{}, bytes.TrimSpace(p)...))
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// The stream ends with the game.
+func (s *EventStream) GameComplete(won bool, piles map[yanhuo.Color]int) {
+	s.MultiObserver.GameComplete(won, piles)
+	s.Close()
+}
+
+// Ends the stream, e.g. when the game is interrupted. Followers are sent the
+// events so far, and then stop.
+func (s *EventStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.changed)
+	}
+}
+
+// Returns the number of events so far.
+func (s *EventStream) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.logs[0])
+}
+
+// Calls f with each event as seen from seat (or by everyone, if seat is -1),
+// along with its position in the game's events, starting from the event at
+// position from. Waits for more events until the stream is closed, stop is
+// closed, or f returns an error, which is returned.
+func (s *EventStream) Follow(seat int, from int, stop <-chan struct{}, f func(id int, event []byte) error) error {
+	if seat < -1 || seat >= len(s.logs)-1 {
+		return fmt.Errorf("No seat %d in a game of %d players", seat, len(s.logs)-1)
+	}
+
+	for next := from; ; {
+		s.mu.Lock()
+		events := s.logs[seat+1]
+		closed := s.closed
+		changed := s.changed
+		s.mu.Unlock()
+
+		for ; next < len(events); next++ {
+			if err := f(next, events[next]); err != nil {
+				return err
+			}
+		}
+		if closed {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// Parses the options for following the stream from a request: "seat" to see
+// the game from that player's seat, and "replay=true" to start from the
+// beginning of the game rather than the next event. A spectator reconnecting
+// with a Last-Event-ID header (as browsers do) carries on after that event.
+func (s *EventStream) followOptions(r *http.Request) (seat int, from int, err error) {
+	seat = -1
+	if v := r.FormValue("seat"); v != "" {
+		if seat, err = strconv.Atoi(v); err != nil || seat < 0 || seat >= len(s.logs)-1 {
+			return 0, 0, fmt.Errorf("Invalid seat: %q", v)
+		}
+	}
+
+	from = s.Len()
+	if v := r.FormValue("replay"); v != "" {
+		replay, err := strconv.ParseBool(v)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Invalid replay: %q", v)
+		}
+		if replay {
+			from = 0
+		}
+	}
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		last, err := strconv.Atoi(v)
+		if err != nil || last < 0 {
+			return 0, 0, fmt.Errorf("Invalid Last-Event-ID: %q", v)
+		}
+		from = last + 1
+	}
+	return seat, from, nil
+}
+
+// Serves the events as Server-Sent Events, each a JSONEvent, with its
+// position in the game as its ID. The stream ends when the game does. See
+// followOptions for the query parameters.
+func (s *EventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	seat, from, err := s.followOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming isn't supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s.Follow(seat, from, r.Context().Done(), func(id int, event []byte) error {
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", id, event); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
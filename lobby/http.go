@@ -0,0 +1,179 @@
+package lobby
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Serves the web client (see the ui directory) at /, and the lobby's HTTP
+// API:
+//
+//	GET  /tables                   lists the tables (TableInfos)
+//	POST /tables                   creates a table from a TableConfig
+//	GET  /tables/{id}              describes a table
+//	POST /tables/{id}/start        starts (or resumes) the game
+//	GET  /tables/{id}/record       the GameRecord of a finished game
+//	GET  /tables/{id}/seats/{seat} WebSocket for playing in a human seat
+//	GET  /tables/{id}/events       Server-Sent Events streaming the game's events
+//	GET  /tables/{id}/watch        WebSocket streaming the game's events
+//
+// Players in human seats are sent httpclient.Transmissions, and answer
+// "ActionRequest"s with an Action, just as remote bots do. Spectators are
+// sent each event as a yanhuo.JSONEvent. By default they see the game as
+// everyone at the table does; "?seat=N" shows it from that seat, hiding the
+// player's own cards, and "?replay=true" starts from the beginning of the
+// game rather than the next event (see EventStream).
+func (l *Lobby) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "tables" {
+		ui.ServeHTTP(w, r)
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case "GET":
+			l.listTables(w, r)
+		case "POST":
+			l.createTable(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	t := l.Table(parts[1])
+	if t == nil {
+		http.Error(w, "No such table", http.StatusNotFound)
+		return
+	}
+
+	route := strings.Join(parts[2:], "/")
+	if len(parts) == 4 && parts[2] == "seats" {
+		route = "seats"
+	}
+
+	method := "GET"
+	var handler func(http.ResponseWriter, *http.Request, *Table)
+	switch route {
+	case "":
+		handler = l.describeTable
+	case "start":
+		method, handler = "POST", l.startTable
+	case "record":
+		handler = l.tableRecord
+	case "seats":
+		handler = l.joinSeat
+	case "events":
+		handler = l.tableEvents
+	case "watch":
+		handler = l.watchTable
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != method {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	handler(w, r, t)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (l *Lobby) listTables(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, l.Tables())
+}
+
+func (l *Lobby) createTable(w http.ResponseWriter, r *http.Request) {
+	var config TableConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		http.Error(w, "Couldn't parse table config: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t, err := l.CreateTable(config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, t.Info())
+}
+
+func (l *Lobby) describeTable(w http.ResponseWriter, r *http.Request, t *Table) {
+	writeJSON(w, t.Info())
+}
+
+func (l *Lobby) startTable(w http.ResponseWriter, r *http.Request, t *Table) {
+	if err := t.Start(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, t.Info())
+}
+
+func (l *Lobby) tableRecord(w http.ResponseWriter, r *http.Request, t *Table) {
+	record := t.Record()
+	if record == nil {
+		http.Error(w, "The game hasn't finished", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, record)
+}
+
+func (l *Lobby) joinSeat(w http.ResponseWriter, r *http.Request, t *Table) {
+	seat, err := strconv.Atoi(path.Base(r.URL.Path))
+	human, ok := t.humans[seat]
+	if err != nil || !ok {
+		http.Error(w, "No such human seat", http.StatusNotFound)
+		return
+	}
+
+	conn, err := Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	human.serve(conn)
+}
+
+func (l *Lobby) tableEvents(w http.ResponseWriter, r *http.Request, t *Table) {
+	t.Events().ServeHTTP(w, r)
+}
+
+func (l *Lobby) watchTable(w http.ResponseWriter, r *http.Request, t *Table) {
+	events := t.Events()
+	seat, from, err := events.followOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// notice if the spectator goes away between events
+	gone := make(chan struct{})
+	go func() {
+		for {
+			if _, err := conn.ReadMessage(); err != nil {
+				close(gone)
+				return
+			}
+		}
+	}()
+
+	events.Follow(seat, from, gone, func(id int, event []byte) error {
+		return conn.WriteMessage(event)
+	})
+}
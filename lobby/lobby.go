@@ -0,0 +1,414 @@
+// Package lobby hosts multiplayer games over HTTP. Tables are created with
+// the rules to play by and who sits in each seat: a built-in strategy (by
+// its registry name), a remote bot reached with httpclient, or a human (or
+// anything else) connected over a WebSocket, speaking the same protocol as
+// the remote bots. People can play from a browser with the web client the
+// lobby serves, which uses that protocol too. Spectators can watch a game's
+// events as they happen, and the records of completed games are kept, and
+// can be saved to a RecordStore.
+//
+// Everything runs in the one process; there are no external services.
+package lobby
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/httpclient"
+	"github.com/mrjones/yanhuo/strategies/registry"
+
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// The kinds of seat at a table.
+const (
+	StrategySeat = "strategy" // a built-in strategy, from the registry
+	HTTPSeat     = "http"     // a remote bot, called with httpclient
+	HumanSeat    = "human"    // whoever connects to the seat's WebSocket
+)
+
+// SeatConfig says who sits in a seat.
+type SeatConfig struct {
+	Kind string
+
+	// For StrategySeats, the strategy's name in the registry.
+	Strategy string `json:",omitempty"`
+	// For HTTPSeats, the bot's URL.
+	URL string `json:",omitempty"`
+	// Optionally, who's playing, for display.
+	Name string `json:",omitempty"`
+}
+
+// Returns who sits in the seat, for archiving: the strategy's name, the
+// bot's URL, or "human".
+func (s SeatConfig) Player() string {
+	switch s.Kind {
+	case StrategySeat:
+		return s.Strategy
+	case HTTPSeat:
+		return s.URL
+	}
+	return s.Kind
+}
+
+// TableConfig describes the game to be played at a table.
+type TableConfig struct {
+	Name  string `json:",omitempty"`
+	Seats []SeatConfig
+
+	// The rules to play by. If nil, the standard rules are used.
+	Rules *yanhuo.Rules `json:",omitempty"`
+
+	// Seeds the deal and the strategies' choices. If zero, one is chosen
+	// when the table is created.
+	Seed int64 `json:",omitempty"`
+}
+
+type TableState string
+
+const (
+	Waiting     TableState = "waiting"     // for the game to be started
+	Playing     TableState = "playing"     // the game is in progress
+	Interrupted TableState = "interrupted" // a player failed; it can be started again to resume
+	Finished    TableState = "finished"    // the game is over, and its record is available
+	Failed      TableState = "failed"      // the game stopped, and can't be resumed
+)
+
+// TableInfo describes a table, for listing in the lobby.
+type TableInfo struct {
+	ID     string
+	Config TableConfig
+	State  TableState
+
+	// Whether each seat is ready to play: human seats need someone to
+	// connect to them.
+	Connected []bool
+
+	Score *int   `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
+
+// The parts of a game in progress the lobby uses.
+type game interface {
+	Play() bool
+	Record() *yanhuo.GameRecord
+	Checkpoint() (*yanhuo.Checkpoint, error)
+}
+
+type Table struct {
+	ID     string
+	Config TableConfig
+
+	store  RecordStore
+	humans map[int]*humanSeat
+
+	mu         sync.Mutex
+	state      TableState
+	err        string
+	checkpoint *yanhuo.Checkpoint
+	record     *yanhuo.GameRecord
+	started    time.Time     // when the game was first started
+	events     *EventStream  // of the current game, or the last one played
+	done       chan struct{} // closed when the game stops
+}
+
+// Lobby holds the tables being played at. It serves an HTTP API for
+// managing them (see ServeHTTP).
+type Lobby struct {
+	store RecordStore
+
+	mu     sync.Mutex
+	tables map[string]*Table
+	order  []*Table // in the order they were created
+}
+
+// Returns an empty lobby. If store isn't nil, the records of completed
+// games are saved to it.
+func New(store RecordStore) *Lobby {
+	return &Lobby{store: store, tables: make(map[string]*Table)}
+}
+
+// Sets up a new table. Returns an error if the configuration doesn't
+// describe a game that can be played.
+func (l *Lobby) CreateTable(config TableConfig) (*Table, error) {
+	rules := yanhuo.StandardRules()
+	if config.Rules != nil {
+		rules = *config.Rules
+	}
+	if err := rules.Validate(len(config.Seats)); err != nil {
+		return nil, err
+	}
+
+	for i, seat := range config.Seats {
+		if err := checkSeat(seat, rules); err != nil {
+			return nil, fmt.Errorf("Seat %d: %v", i, err)
+		}
+	}
+
+	if config.Seed == 0 {
+		config.Seed = time.Now().UTC().UnixNano()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := fmt.Sprintf("t%d", len(l.order)+1)
+	t := &Table{
+		ID:     id,
+		Config: config,
+		store:  l.store,
+		humans: make(map[int]*humanSeat),
+		state:  Waiting,
+		events: NewEventStream(id, len(config.Seats)),
+	}
+	for i, seat := range config.Seats {
+		if seat.Kind == HumanSeat {
+			t.humans[i] = newHumanSeat(i)
+		}
+	}
+
+	l.tables[t.ID] = t
+	l.order = append(l.order, t)
+	return t, nil
+}
+
+func checkSeat(seat SeatConfig, rules yanhuo.Rules) error {
+	switch seat.Kind {
+	case StrategySeat:
+		s, err := registry.New(seat.Strategy, 1)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.(yanhuo.CheatingStrategy); ok {
+			return fmt.Errorf("Strategy %q cheats, so can't play in the lobby", seat.Strategy)
+		}
+		if c, ok := s.(yanhuo.RulesChecker); ok {
+			if err := c.CheckRules(rules); err != nil {
+				return fmt.Errorf("Strategy %q can't play by the table's rules: %v", seat.Strategy, err)
+			}
+		}
+	case HTTPSeat:
+		u, err := url.Parse(seat.URL)
+		if err != nil {
+			return err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("Bot URL should be http or https: %q", seat.URL)
+		}
+	case HumanSeat:
+	default:
+		return fmt.Errorf("Unknown kind of seat: %q", seat.Kind)
+	}
+	return nil
+}
+
+// Returns the table with the given ID, or nil if there isn't one.
+func (l *Lobby) Table(id string) *Table {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tables[id]
+}
+
+// Describes every table, oldest first.
+func (l *Lobby) Tables() []TableInfo {
+	l.mu.Lock()
+	tables := append([]*Table{}, l.order...)
+	l.mu.Unlock()
+
+	infos := []TableInfo{}
+	for _, t := range tables {
+		infos = append(infos, t.Info())
+	}
+	return infos
+}
+
+func (t *Table) Info() TableInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info := TableInfo{
+		ID:        t.ID,
+		Config:    t.Config,
+		State:     t.state,
+		Connected: make([]bool, len(t.Config.Seats)),
+		Error:     t.err,
+	}
+	for i := range t.Config.Seats {
+		human, ok := t.humans[i]
+		info.Connected[i] = !ok || human.connected()
+	}
+	if t.record != nil {
+		score := t.record.Score
+		info.Score = &score
+	}
+	return info
+}
+
+// Returns the record of the game, once it has finished.
+func (t *Table) Record() *yanhuo.GameRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.record
+}
+
+// Creates the players for a game at the table.
+func (t *Table) players() ([]yanhuo.PlayerStrategy, error) {
+	players := make([]yanhuo.PlayerStrategy, len(t.Config.Seats))
+	for i, seat := range t.Config.Seats {
+		switch seat.Kind {
+		case StrategySeat:
+			s, err := registry.New(seat.Strategy, t.Config.Seed+int64(i))
+			if err != nil {
+				return nil, err
+			}
+			players[i] = s
+		case HTTPSeat:
+			u, err := url.Parse(seat.URL)
+			if err != nil {
+				return nil, err
+			}
+			players[i] = httpclient.NewHttpClientStrategy(u)
+		case HumanSeat:
+			players[i] = t.humans[i]
+		}
+	}
+	return players, nil
+}
+
+// Starts the game, or resumes it if it was interrupted. Every human seat
+// needs someone connected to it.
+func (t *Table) Start() error {
+	t.mu.Lock()
+	if t.state != Waiting && t.state != Interrupted {
+		t.mu.Unlock()
+		return fmt.Errorf("Can't start a game which is %s", t.state)
+	}
+	for i, human := range t.humans {
+		if !human.connected() {
+			t.mu.Unlock()
+			return fmt.Errorf("Seat %d is waiting for a player", i)
+		}
+	}
+
+	players, err := t.players()
+	if err != nil {
+		t.mu.Unlock()
+		return err
+	}
+	checkpoint := t.checkpoint
+	if checkpoint != nil {
+		// the game is played back from the start, so it's streamed again
+		// from the start: spectators who followed the first attempt see the
+		// same events again, with the same IDs
+		t.events = NewEventStream(t.ID, len(t.Config.Seats))
+	}
+	events := t.events
+	done := make(chan struct{})
+
+	// marked as playing before the lock is released, so that it can't be
+	// started twice
+	previous, previousErr := t.state, t.err
+	t.state = Playing
+	t.err = ""
+	t.done = done
+	t.mu.Unlock()
+
+	// resuming plays the game back, which can take a while, and talks to
+	// the players, so happens without holding the lock
+	var g game
+	observers := []yanhuo.Observer{events}
+	if checkpoint != nil {
+		g, err = yanhuo.Resume(checkpoint, players, observers)
+	} else {
+		g, err = yanhuo.InitializeGameWithOptions(players, observers, yanhuo.GameOptions{
+			Seed:  t.Config.Seed,
+			Rules: t.Config.Rules,
+		})
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.state, t.err = previous, previousErr
+		close(done)
+		return err
+	}
+	if t.started.IsZero() {
+		t.started = time.Now()
+	}
+	go t.run(g, events, done)
+	return nil
+}
+
+func (t *Table) run(g game, events *EventStream, done chan struct{}) {
+	defer close(done)
+	defer events.Close()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		checkpoint, err := g.Checkpoint()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.err = fmt.Sprint(r)
+		if err != nil {
+			t.state = Failed
+			t.checkpoint = nil
+			return
+		}
+		t.state = Interrupted
+		t.checkpoint = checkpoint
+	}()
+
+	g.Play()
+	record := g.Record()
+
+	t.mu.Lock()
+	t.state = Finished
+	t.record = record
+	t.checkpoint = nil
+	t.mu.Unlock()
+
+	if t.store != nil {
+		players := []string{}
+		for _, seat := range t.Config.Seats {
+			players = append(players, seat.Player())
+		}
+		game := CompletedGame{
+			TableID:  t.ID,
+			Players:  players,
+			Started:  t.started,
+			Finished: time.Now(),
+			Record:   record,
+		}
+		if err := t.store.SaveRecord(game); err != nil {
+			t.mu.Lock()
+			t.err = fmt.Sprintf("Couldn't save the record: %v", err)
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Returns the events of the game being played, or of the last one played
+// if it has stopped.
+func (t *Table) Events() *EventStream {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.events
+}
+
+// Waits until the game stops, either because it finished or because it was
+// interrupted. Returns immediately if it isn't being played.
+func (t *Table) Wait() {
+	t.mu.Lock()
+	done := t.done
+	t.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
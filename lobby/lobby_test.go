@@ -0,0 +1,628 @@
+package lobby
+
+import (
+	"github.com/mrjones/yanhuo/archive"
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/httpclient"
+
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postJSON(t *testing.T, url string, v interface{}) *http.Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func createTable(t *testing.T, s *httptest.Server, config TableConfig) TableInfo {
+	resp := postJSON(t, s.URL+"/tables", config)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := ioutil.ReadAll(resp.Body)
+		t.Fatalf("Couldn't create table: %s: %s", resp.Status, body)
+	}
+	var info TableInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func startTable(t *testing.T, s *httptest.Server, id string) {
+	resp := postJSON(t, s.URL+"/tables/"+id+"/start", nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		t.Fatalf("Couldn't start table: %s: %s", resp.Status, body)
+	}
+}
+
+func dial(t *testing.T, s *httptest.Server, path string) *Conn {
+	conn, err := Dial("ws" + strings.TrimPrefix(s.URL, "http") + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestBotTable(t *testing.T) {
+	dir := t.TempDir()
+	l := New(DirStore(dir))
+	s := httptest.NewServer(l)
+	defer s.Close()
+
+	info := createTable(t, s, TableConfig{
+		Name: "bots",
+		Seats: []SeatConfig{
+			{Kind: StrategySeat, Strategy: "heuristic"},
+			{Kind: StrategySeat, Strategy: "heuristic"},
+			{Kind: StrategySeat, Strategy: "safediscard"},
+		},
+		Seed: 5,
+	})
+	if info.State != Waiting || info.ID == "" {
+		t.Fatalf("Unexpected new table: %+v", info)
+	}
+
+	startTable(t, s, info.ID)
+
+	// however far the game has got, the watcher sees it from the start
+	watcher := dial(t, s, "/tables/"+info.ID+"/watch?replay=true")
+	defer watcher.Close()
+
+	events := []string{}
+	for {
+		data, err := watcher.ReadMessage()
+		if err != nil {
+			break
+		}
+		var e yanhuo.JSONEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e.Event)
+	}
+	if len(events) < 2 || events[0] != "GameStart" || events[len(events)-1] != "GameComplete" {
+		t.Errorf("Spectator should have seen the whole game, got %v", events)
+	}
+
+	l.Table(info.ID).Wait()
+	resp, err := http.Get(s.URL + "/tables/" + info.ID + "/record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var record yanhuo.GameRecord
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Seed != 5 || record.NumPlayers != 3 || len(record.Turns) == 0 {
+		t.Errorf("Unexpected record: seed %d, %d players, %d turns", record.Seed, record.NumPlayers, len(record.Turns))
+	}
+
+	saved, err := filepath.Glob(filepath.Join(dir, "*-"+info.ID+".json"))
+	if err != nil || len(saved) != 1 {
+		t.Errorf("Expected the record to be saved, found %v", saved)
+	}
+
+	if tables := l.Tables(); len(tables) != 1 || tables[0].State != Finished || *tables[0].Score != record.Score {
+		t.Errorf("Unexpected tables: %+v", tables)
+	}
+}
+
+func TestArchiveStore(t *testing.T) {
+	a, err := archive.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(ArchiveStore{a})
+
+	table, err := l.CreateTable(TableConfig{
+		Seats: []SeatConfig{{Kind: StrategySeat, Strategy: "heuristic"}, {Kind: StrategySeat, Strategy: "safediscard"}},
+		Seed:  4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Start(); err != nil {
+		t.Fatal(err)
+	}
+	table.Wait()
+
+	games := a.Query(archive.Query{Player: "safediscard"})
+	if len(games) != 1 {
+		t.Fatalf("Expected the game to be archived, found %d", len(games))
+	}
+	if g := games[0]; g.Seed != 4 || g.Score != table.Record().Score || g.Players[0] != "heuristic" ||
+		g.Started.IsZero() || g.Finished.Before(g.Started) {
+		t.Errorf("Unexpected archived game: %+v", g)
+	}
+}
+
+// Reads Server-Sent Events until the stream ends, returning their IDs and
+// data.
+func readEvents(t *testing.T, url string, lastEventID string) ([]string, []yanhuo.JSONEvent) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected response: %s %s", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	ids := []string{}
+	events := []yanhuo.JSONEvent{}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: "):
+			var e yanhuo.JSONEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, e)
+		case line != "":
+			t.Errorf("Unexpected line in event stream: %q", line)
+		}
+	}
+	return ids, events
+}
+
+func TestEventStream(t *testing.T) {
+	l := New(nil)
+	s := httptest.NewServer(l)
+	defer s.Close()
+
+	info := createTable(t, s, TableConfig{
+		Seats: []SeatConfig{{Kind: StrategySeat, Strategy: "heuristic"}, {Kind: StrategySeat, Strategy: "heuristic"}},
+		Seed:  8,
+	})
+	startTable(t, s, info.ID)
+	l.Table(info.ID).Wait()
+
+	url := s.URL + "/tables/" + info.ID + "/events"
+	ids, events := readEvents(t, url+"?replay=true", "")
+	if len(events) < 2 || events[0].Event != "GameStart" || events[len(events)-1].Event != "GameComplete" {
+		t.Fatalf("Expected the whole game, got %d events", len(events))
+	}
+	for i, id := range ids {
+		if id != strconv.Itoa(i) {
+			t.Fatalf("Event %d has ID %s", i, id)
+		}
+	}
+	if events[0].Hands[0][0].IsUnknown() || events[0].Hands[1][0].IsUnknown() {
+		t.Errorf("Spectators should see every hand: %v", events[0].Hands)
+	}
+
+	_, seen := readEvents(t, url+"?replay=true&seat=1", "")
+	if len(seen) != len(events) {
+		t.Fatalf("Expected %d events from seat 1, got %d", len(events), len(seen))
+	}
+	if seen[0].Hands[0][0].IsUnknown() || !seen[0].Hands[1][0].IsUnknown() {
+		t.Errorf("Seat 1 should only see the other hand: %v", seen[0].Hands)
+	}
+	for i, e := range seen {
+		if e.Event == "Draw" && *e.Player == 1 && !e.Card.IsUnknown() {
+			t.Errorf("Event %d shows seat 1 the card it drew", i)
+		}
+	}
+
+	// a spectator reconnecting carries on where they left off
+	ids, rest := readEvents(t, url, strconv.Itoa(len(events)-3))
+	if len(rest) != 2 || ids[0] != strconv.Itoa(len(events)-2) {
+		t.Errorf("Expected the last two events, got %v", ids)
+	}
+
+	// by default, spectators of a finished game see nothing
+	if _, none := readEvents(t, url, ""); len(none) != 0 {
+		t.Errorf("Expected no events, got %d", len(none))
+	}
+
+	resp, err := http.Get(url + "?seat=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an invalid seat to be rejected, got %s", resp.Status)
+	}
+}
+
+func TestBadTables(t *testing.T) {
+	noRedTokens := yanhuo.StandardRules()
+	noRedTokens.RedTokens = 0
+	rainbow := yanhuo.StandardRules()
+	rainbow.Suits = map[yanhuo.Color]yanhuo.SuitRules{yanhuo.RED: {ColorHints: yanhuo.TouchAlways}}
+
+	l := New(nil)
+	for _, config := range []TableConfig{
+		{Seats: []SeatConfig{{Kind: StrategySeat, Strategy: "heuristic"}}},
+		{Seats: []SeatConfig{{Kind: StrategySeat, Strategy: "nonesuch"}, {Kind: HumanSeat}}},
+		{Seats: []SeatConfig{{Kind: StrategySeat, Strategy: "oracle"}, {Kind: HumanSeat}}},
+		{Seats: []SeatConfig{{Kind: HTTPSeat, URL: "ftp://bot"}, {Kind: HumanSeat}}},
+		{Seats: []SeatConfig{{Kind: "robot"}, {Kind: HumanSeat}}},
+		{Seats: []SeatConfig{{Kind: HumanSeat}, {Kind: HumanSeat}}, Rules: &noRedTokens},
+		{Seats: []SeatConfig{{Kind: StrategySeat, Strategy: "conventions"}, {Kind: HumanSeat}}, Rules: &rainbow},
+	} {
+		if _, err := l.CreateTable(config); err == nil {
+			t.Errorf("Expected an error creating table %+v", config)
+		}
+	}
+
+	table, err := l.CreateTable(TableConfig{Seats: []SeatConfig{{Kind: HumanSeat}, {Kind: HumanSeat}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := table.Start(); err == nil {
+		t.Errorf("Expected an error starting a table with empty seats")
+	}
+}
+
+// Plays a human seat by always discarding the first card, after first
+// trying something illegal. Stops after discarding `turns` times, or when
+// the connection ends. Returns the messages received.
+func playHuman(t *testing.T, conn *Conn, turns int) []httpclient.Transmission {
+	received := []httpclient.Transmission{}
+	for turns > 0 {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var message httpclient.Transmission
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, message)
+
+		if message.MessageType != "ActionRequest" {
+			continue
+		}
+		conn.WriteJSON(yanhuo.Action{Play: &yanhuo.PlayAction{Index: 99}})
+		data, err = conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		var reply httpclient.Transmission
+		if err := json.Unmarshal(data, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.MessageType != "Error" {
+			t.Errorf("Expected an illegal play to be rejected, got %+v", reply)
+		}
+		received = append(received, reply)
+
+		conn.WriteJSON(yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: 0}})
+		turns--
+	}
+	return received
+}
+
+func count(messages []httpclient.Transmission, messageType string) int {
+	n := 0
+	for _, m := range messages {
+		if m.MessageType == messageType {
+			n++
+		}
+	}
+	return n
+}
+
+func TestHumanSeat(t *testing.T) {
+	l := New(nil)
+	s := httptest.NewServer(l)
+	defer s.Close()
+
+	info := createTable(t, s, TableConfig{
+		Seats: []SeatConfig{{Kind: HumanSeat, Name: "me"}, {Kind: StrategySeat, Strategy: "heuristic"}},
+		Seed:  3,
+	})
+	table := l.Table(info.ID)
+
+	conn := dial(t, s, "/tables/"+info.ID+"/seats/0")
+	for !table.Info().Connected[0] {
+	}
+	startTable(t, s, info.ID)
+
+	// leave after 3 turns: the game is interrupted, either while waiting for
+	// the player or when it next asks them to act
+	received := playHuman(t, conn, 3)
+	conn.Close()
+	if count(received, "StartGame") != 1 || count(received, "Error") != 3 {
+		t.Errorf("Unexpected messages: %+v", received)
+	}
+
+	table.Wait()
+	if info := table.Info(); info.State != Interrupted || !strings.Contains(info.Error, "Player 0") {
+		t.Fatalf("Expected the game to be interrupted: %+v", info)
+	}
+
+	conn = dial(t, s, "/tables/"+info.ID+"/seats/0")
+	defer conn.Close()
+	for !table.Info().Connected[0] {
+	}
+	startTable(t, s, info.ID)
+
+	done := make(chan []httpclient.Transmission)
+	go func() {
+		done <- playHuman(t, conn, 100)
+	}()
+	table.Wait()
+	conn.Close()
+	received = <-done
+
+	if info := table.Info(); info.State != Finished {
+		t.Fatalf("Expected the resumed game to finish: %+v", info)
+	}
+	// the resumed player is brought up to date: the start of the game, and
+	// every action so far, including their own
+	if count(received, "StartGame") != 1 || count(received, "Observation") < 6 {
+		t.Errorf("Resumed player wasn't brought up to date: %d messages", len(received))
+	}
+
+	// the hands tracked for Observations should agree with the game's
+	for i := 1; i < len(received); i++ {
+		observed, requested := received[i-1], received[i]
+		if observed.MessageType != "Observation" || observed.GameState == nil || requested.MessageType != "ActionRequest" {
+			continue
+		}
+		if fmt.Sprint(observed.GameState.OtherPlayersCards) != fmt.Sprint(requested.GameState.OtherPlayersCards) {
+			t.Errorf("Tracked hands %v, but the game has %v",
+				observed.GameState.OtherPlayersCards, requested.GameState.OtherPlayersCards)
+		}
+		if requested.Board == nil || len(requested.Board.Knowledge["0"]) != requested.GameState.MyCardCount {
+			t.Errorf("Expected the board with the request: %+v", requested.Board)
+		}
+	}
+
+	record := table.Record()
+	for i, turn := range record.Turns {
+		if turn.Player == 0 && (turn.Action.Discard == nil || turn.Action.Discard.Index != 0) {
+			t.Errorf("Turn %d should be the human's discard: %s", i, turn.Action.DebugString())
+		}
+	}
+	if err := yanhuo.Replay(record, nil); err != nil {
+		t.Errorf("Record of the resumed game doesn't replay: %v", err)
+	}
+}
+
+// Returns a seat in a new game, served by s, and the other player's hand.
+func serveSeat(t *testing.T) (*humanSeat, *httptest.Server, map[yanhuo.PlayerIndex][]yanhuo.Card) {
+	seat := newHumanSeat(0)
+	game, err := yanhuo.InitializeGame([]yanhuo.PlayerStrategy{seat, newHumanSeat(1)}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seat.ObserveView(game.View())
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, err := Upgrade(w, r); err == nil {
+			seat.serve(conn)
+		}
+	}))
+	return seat, s, map[yanhuo.PlayerIndex][]yanhuo.Card{1: yanhuo.NewDeck()[:5]}
+}
+
+func nextMessage(t *testing.T, conn *Conn) httpclient.Transmission {
+	received := make(chan httpclient.Transmission)
+	go func() {
+		var message httpclient.Transmission
+		if data, err := conn.ReadMessage(); err == nil {
+			json.Unmarshal(data, &message)
+		}
+		received <- message
+	}()
+	select {
+	case message := <-received:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a message")
+	}
+	return httpclient.Transmission{}
+}
+
+// Asks the seat to act, returning the action it takes.
+func startAct(t *testing.T, seat *humanSeat, hands map[yanhuo.PlayerIndex][]yanhuo.Card) chan yanhuo.Action {
+	actions := make(chan yanhuo.Action, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("Act failed: %v", r)
+				actions <- yanhuo.Action{}
+			}
+		}()
+		actions <- seat.Act(0, hands, 5, 8, 3)
+	}()
+	return actions
+}
+
+func TestHumanSeatLateReply(t *testing.T) {
+	seat, s, hands := serveSeat(t)
+	defer s.Close()
+	conn := dial(t, s, "/")
+	defer conn.Close()
+	for !seat.connected() {
+	}
+
+	// the second answer arrives after the first has been accepted
+	actions := startAct(t, seat, hands)
+	if message := nextMessage(t, conn); message.MessageType != "ActionRequest" {
+		t.Fatalf("Expected an ActionRequest, got %+v", message)
+	}
+	conn.WriteJSON(yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: 0}})
+	conn.WriteJSON(yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: 1}})
+	if action := <-actions; action.Discard == nil || action.Discard.Index != 0 {
+		t.Errorf("Expected the first answer, got %s", action.DebugString())
+	}
+	if message := nextMessage(t, conn); message.MessageType != "Error" {
+		t.Errorf("Expected the late answer to be rejected, got %+v", message)
+	}
+
+	// and isn't taken as the answer to the next request
+	actions = startAct(t, seat, hands)
+	if message := nextMessage(t, conn); message.MessageType != "ActionRequest" {
+		t.Fatalf("Expected an ActionRequest, got %+v", message)
+	}
+	conn.WriteJSON(yanhuo.Action{Play: &yanhuo.PlayAction{Index: 0}})
+	if action := <-actions; action.Play == nil {
+		t.Errorf("Expected the answer to the second request, got %s", action.DebugString())
+	}
+}
+
+func TestHumanSeatReconnect(t *testing.T) {
+	seat, s, hands := serveSeat(t)
+	defer s.Close()
+	first := dial(t, s, "/")
+	defer first.Close()
+	for !seat.connected() {
+	}
+
+	actions := startAct(t, seat, hands)
+	if message := nextMessage(t, first); message.MessageType != "ActionRequest" {
+		t.Fatalf("Expected an ActionRequest, got %+v", message)
+	}
+
+	// the player reconnects, e.g. after reloading the page, and is asked
+	// again on the new connection
+	second := dial(t, s, "/")
+	defer second.Close()
+	if message := nextMessage(t, second); message.MessageType != "ActionRequest" {
+		t.Fatalf("Expected the ActionRequest again, got %+v", message)
+	}
+	second.WriteJSON(yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: 2}})
+	if action := <-actions; action.Discard == nil || action.Discard.Index != 2 {
+		t.Errorf("Expected the answer from the new connection, got %s", action.DebugString())
+	}
+}
+
+func TestUI(t *testing.T) {
+	s := httptest.NewServer(New(nil))
+	defer s.Close()
+
+	for path, expected := range map[string]string{
+		"/":          "<title>",
+		"/client.js": "ActionRequest",
+	} {
+		resp, err := http.Get(s.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), expected) {
+			t.Errorf("Unexpected response for %s: %s", path, resp.Status)
+		}
+	}
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	received := make(chan string)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- string(data)
+			conn.WriteMessage(data)
+		}
+	}))
+	defer s.Close()
+
+	conn := dial(t, s, "/")
+	for _, size := range []int{0, 10, 200, 70000} {
+		message := strings.Repeat("x", size)
+		if err := conn.WriteMessage([]byte(message)); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-received; got != message {
+			t.Errorf("Server got %d bytes, expected %d", len(got), size)
+		}
+		echo, err := conn.ReadMessage()
+		if err != nil || string(echo) != message {
+			t.Errorf("Echo of %d bytes came back as %d bytes (%v)", size, len(echo), err)
+		}
+	}
+	conn.Close()
+	if _, ok := <-received; ok {
+		t.Errorf("Server should have seen the connection close")
+	}
+
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected plain HTTP requests to be rejected, got %s", resp.Status)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, err := Upgrade(w, r); err == nil {
+			conn.Close()
+		}
+	}))
+	defer s.Close()
+
+	for origin, expected := range map[string]int{
+		"":                   http.StatusSwitchingProtocols,
+		s.URL:                http.StatusSwitchingProtocols,
+		"http://example.com": http.StatusForbidden,
+		"null":               http.StatusForbidden,
+	} {
+		req, err := http.NewRequest("GET", s.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("Handshake from origin %q: expected %d, got %s", origin, expected, resp.Status)
+		}
+	}
+}
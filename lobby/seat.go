@@ -0,0 +1,318 @@
+package lobby
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/httpclient"
+
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// A message from the player connected to a seat, the error which ended
+// their connection, or news that they've just connected.
+type reply struct {
+	conn   *Conn
+	data   []byte
+	err    error
+	joined bool
+}
+
+// One ActionRequest, waiting for the player's answer.
+type request struct {
+	replies chan reply
+	done    chan struct{} // closed once Act has returned
+}
+
+// Passes r to the waiting Act. Returns false, dropping r, if Act has
+// already returned.
+func (req *request) reply(r reply) bool {
+	select {
+	case req.replies <- r:
+		return true
+	case <-req.done:
+		return false
+	}
+}
+
+// humanSeat is a PlayerStrategy played by whoever is connected to the seat
+// over a WebSocket. It speaks the same protocol as httpclient: the player is
+// sent a Transmission for each call, and answers "ActionRequest"s with an
+// Action. Actions which aren't legal are rejected with an "Error" message,
+// and the player is asked again.
+//
+// So that a client can draw the table, every message carries the Board, and
+// Observations also carry the GameState after the action, which is tracked
+// as the game goes on.
+type humanSeat struct {
+	index int
+
+	// only used from the game's goroutine
+	view  *yanhuo.View
+	rules yanhuo.Rules
+	hands map[yanhuo.PlayerIndex][]yanhuo.Card // the other players'
+	drew  bool                                 // during the current action
+
+	mu      sync.Mutex
+	conn    *Conn
+	request *request // while waiting for the player to act
+}
+
+func newHumanSeat(index int) *humanSeat {
+	return &humanSeat{index: index}
+}
+
+func (s *humanSeat) connected() bool {
+	return s.current() != nil
+}
+
+// Seats the player on the other end of conn, replacing anyone already
+// connected, and passes on what they send until they go away.
+func (s *humanSeat) serve(conn *Conn) {
+	s.mu.Lock()
+	old := s.conn
+	s.conn = conn
+	req := s.request
+	s.mu.Unlock()
+
+	// closing can block while the close frame is sent, so happens without
+	// holding the lock
+	if old != nil {
+		old.Close()
+	}
+	// a waiting Act needs to ask the new player
+	if req != nil {
+		req.reply(reply{conn: conn, joined: true})
+	}
+
+	for {
+		data, err := conn.ReadMessage()
+
+		s.mu.Lock()
+		if err != nil && s.conn == conn {
+			s.conn = nil
+		}
+		req := s.request
+		s.mu.Unlock()
+
+		if err != nil {
+			conn.Close()
+			// a waiting Act needs to know the player left
+			if req != nil {
+				req.reply(reply{conn: conn, err: err})
+			}
+			return
+		}
+
+		// an answer which arrives after Act has returned is dropped, rather
+		// than taken as the answer to the next request
+		if req == nil || !req.reply(reply{conn: conn, data: data}) {
+			conn.WriteJSON(httpclient.Transmission{MessageType: "Error", Error: "It isn't your turn"})
+		}
+	}
+}
+
+// Returns the connection of the player seated now, if any.
+func (s *humanSeat) current() *Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn
+}
+
+// Sends t to the connected player. Messages to a player who isn't connected
+// are dropped: they'll be brought up to date if the game is resumed.
+func (s *humanSeat) send(t httpclient.Transmission) {
+	if conn := s.current(); conn != nil {
+		conn.WriteJSON(t)
+	}
+}
+
+func (s *humanSeat) ObserveView(v *yanhuo.View) {
+	s.view = v
+	s.rules = v.Rules()
+}
+
+func (s *humanSeat) board() *httpclient.Board {
+	return httpclient.NewBoard(s.view, len(s.hands)+1)
+}
+
+func (s *humanSeat) setHands(otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card) {
+	s.hands = make(map[yanhuo.PlayerIndex][]yanhuo.Card)
+	for p, cards := range otherPlayersCards {
+		s.hands[p] = append([]yanhuo.Card{}, cards...)
+	}
+}
+
+func (s *humanSeat) StartGame(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) {
+	s.setHands(otherPlayersCards)
+	s.send(httpclient.Transmission{
+		MessageType: "StartGame",
+		GameState:   httpclient.NewGameState(myPlayerIndex, otherPlayersCards, myNumCards, blueTokens, redTokens),
+		Board:       s.board(),
+	})
+}
+
+func (s *humanSeat) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	s.setHands(otherPlayersCards)
+
+	req := &request{replies: make(chan reply), done: make(chan struct{})}
+	s.mu.Lock()
+	s.request = req
+	conn := s.conn
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.request = nil
+		s.mu.Unlock()
+		close(req.done)
+	}()
+
+	if conn == nil {
+		panic(fmt.Sprintf("Player %d is not connected", s.index))
+	}
+	request := httpclient.Transmission{
+		MessageType: "ActionRequest",
+		GameState:   httpclient.NewGameState(myPlayerIndex, otherPlayersCards, myNumCards, blueTokens, redTokens),
+		Board:       s.board(),
+	}
+	conn.WriteJSON(request)
+
+	for {
+		r := <-req.replies
+		if r.joined {
+			// the player reconnected, and needs asking again, unless they've
+			// reconnected again since
+			if r.conn != conn && r.conn == s.current() {
+				conn = r.conn
+				conn.WriteJSON(request)
+			}
+			continue
+		}
+		if r.conn != conn {
+			// left over from an earlier connection
+			continue
+		}
+		if r.err != nil {
+			if current := s.current(); current != nil && current != conn {
+				// the new connection will be asked once it's seated
+				continue
+			}
+			panic(fmt.Sprintf("Player %d disconnected: %v", s.index, r.err))
+		}
+
+		var action yanhuo.Action
+		err := json.Unmarshal(r.data, &action)
+		if err == nil {
+			err = s.check(action, otherPlayersCards, myNumCards, blueTokens)
+		}
+		if err != nil {
+			conn.WriteJSON(httpclient.Transmission{MessageType: "Error", Error: err.Error()})
+			continue
+		}
+		return action
+	}
+}
+
+// Returns an error if the engine would reject action.
+func (s *humanSeat) check(
+	action yanhuo.Action,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int) error {
+	if !action.IsValid() {
+		return fmt.Errorf("Invalid action: %s", action.InvalidReason())
+	}
+
+	switch {
+	case action.Play != nil:
+		if action.Play.Index < 0 || int(action.Play.Index) >= myNumCards {
+			return fmt.Errorf("No card %d to play", action.Play.Index)
+		}
+	case action.Discard != nil:
+		if action.Discard.Index < 0 || int(action.Discard.Index) >= myNumCards {
+			return fmt.Errorf("No card %d to discard", action.Discard.Index)
+		}
+	case action.GiveInformation != nil:
+		hint := action.GiveInformation
+		cards, ok := otherPlayersCards[hint.PlayerIndex]
+		if !ok {
+			return fmt.Errorf("Can't give information to player %d", hint.PlayerIndex)
+		}
+		if blueTokens < 1 {
+			return fmt.Errorf("No blue tokens left to give information")
+		}
+
+		var expected *yanhuo.GiveInformationAction
+		if hint.Color != nil {
+			if !s.rules.CanName(hint.Color.Color) {
+				return fmt.Errorf("Can't name that color in a hint")
+			}
+			expected = s.rules.ColorHint(hint.PlayerIndex, cards, hint.Color.Color)
+		} else {
+			expected = s.rules.ValueHint(hint.PlayerIndex, cards, hint.Value.Value)
+		}
+		if fmt.Sprint(expected.Cards) != fmt.Sprint(hint.Cards) {
+			return fmt.Errorf("That hint touches cards %v", expected.Cards)
+		}
+	}
+	return nil
+}
+
+func (s *humanSeat) ObserveDiscard(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {}
+
+func (s *humanSeat) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {}
+
+func (s *humanSeat) ObserveDraw(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	// the new card takes the place of the one played or discarded
+	s.hands[p][i] = c
+	s.drew = true
+}
+
+func (s *humanSeat) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+	if hand, ok := s.hands[actor]; ok && !s.drew {
+		// once the deck is empty, played and discarded cards aren't replaced
+		if action.Play != nil {
+			s.hands[actor] = append(hand[:action.Play.Index], hand[action.Play.Index+1:]...)
+		}
+		if action.Discard != nil {
+			s.hands[actor] = append(hand[:action.Discard.Index], hand[action.Discard.Index+1:]...)
+		}
+	}
+	s.drew = false
+
+	me := yanhuo.PlayerIndex(s.index)
+	s.send(httpclient.Transmission{
+		MessageType: "Observation",
+		Observation: &httpclient.Observation{Actor: actor, Action: action},
+		GameState: httpclient.NewGameState(me, s.hands, len(s.view.Knowledge(me)),
+			s.view.BlueTokens(), s.view.RedTokens()),
+		Board: s.board(),
+	})
+}
+
+// When a game is resumed, the player is shown their own earlier actions
+// too, so that a client can rebuild the game from the messages it was sent.
+// This happens before the action is taken, so there's no board to send: the
+// next Observation brings it up to date.
+func (s *humanSeat) Rejoin(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int,
+	action yanhuo.Action) {
+	s.send(httpclient.Transmission{
+		MessageType: "Observation",
+		Observation: &httpclient.Observation{Actor: myPlayerIndex, Action: action},
+	})
+}
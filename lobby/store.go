@@ -0,0 +1,52 @@
+package lobby
+
+import (
+	"github.com/mrjones/yanhuo/archive"
+	"github.com/mrjones/yanhuo/core"
+
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+)
+
+// CompletedGame is a game played to the end at a table.
+type CompletedGame struct {
+	TableID string
+	// Who sat in each seat (see SeatConfig.Player).
+	Players  []string
+	Started  time.Time
+	Finished time.Time
+	Record   *yanhuo.GameRecord
+}
+
+// RecordStore keeps the records of completed games.
+type RecordStore interface {
+	SaveRecord(game CompletedGame) error
+}
+
+// DirStore saves each record as JSON in the named directory, in a file named
+// after when the game finished and the table it was played at, since table
+// IDs are only unique within one lobby.
+type DirStore string
+
+func (d DirStore) SaveRecord(game CompletedGame) error {
+	data, err := json.MarshalIndent(game.Record, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.json", game.Finished.UTC().Format("20060102-150405"), game.TableID)
+	return ioutil.WriteFile(filepath.Join(string(d), name), data, 0644)
+}
+
+// ArchiveStore adds each game to an archive, where it can be found by who
+// played, its score and so on.
+type ArchiveStore struct {
+	Archive *archive.Archive
+}
+
+func (s ArchiveStore) SaveRecord(game CompletedGame) error {
+	_, err := s.Archive.Add(game.Record, game.Players, game.Started, game.Finished)
+	return err
+}
@@ -0,0 +1,21 @@
+package lobby
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The web client for people playing in human seats, served at the root of
+// the lobby.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+var ui = func() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}()
@@ -0,0 +1,362 @@
+// A client for playing in a human seat. It speaks the same protocol as the
+// remote bots (see httpclient.Transmission), over the seat's WebSocket, and
+// draws the table from the Board and GameState sent with each message.
+
+"use strict";
+
+const COLORS = ["WHITE", "RED", "BLUE", "YELLOW", "GREEN"];
+const VALUES = [1, 2, 3, 4, 5];
+
+// How hints touch the cards of a suit (see yanhuo.Touch).
+const TOUCH_MATCHING = 0, TOUCH_ALWAYS = 1, TOUCH_NEVER = 2;
+
+let game = null;
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    if (k === "onclick") {
+      e.onclick = v;
+    } else {
+      e.setAttribute(k, v);
+    }
+  }
+  for (const c of children) {
+    e.append(c);
+  }
+  return e;
+}
+
+async function api(method, path, body) {
+  const resp = await fetch(path, {
+    method: method,
+    headers: {"Content-Type": "application/json"},
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (!resp.ok) {
+    throw new Error((await resp.text()).trim() || resp.statusText);
+  }
+  return resp.json();
+}
+
+function seatName(seat) {
+  if (seat.Name) {
+    return seat.Name;
+  }
+  switch (seat.Kind) {
+    case "strategy": return seat.Strategy;
+    case "http": return seat.URL;
+  }
+  return seat.Kind;
+}
+
+//
+// The list of tables
+//
+
+async function refreshTables() {
+  let tables;
+  try {
+    tables = await api("GET", "/tables");
+  } catch (e) {
+    $("lobby-error").textContent = e.message;
+    return;
+  }
+  $("lobby-error").textContent = "";
+
+  const body = $("tables").querySelector("tbody");
+  body.replaceChildren();
+  for (const t of tables) {
+    const seats = el("td");
+    t.Config.Seats.forEach((seat, i) => {
+      seats.append(seatName(seat));
+      if (seat.Kind === "human" && !t.Connected[i] && t.State !== "finished") {
+        seats.append(" ", el("button", {onclick: () => sit(t, i)}, "Sit"));
+      }
+      if (i < t.Config.Seats.length - 1) {
+        seats.append(", ");
+      }
+    });
+
+    const actions = el("td");
+    if (t.State === "finished") {
+      actions.append(el("a", {href: "/tables/" + t.ID + "/record"}, "Record"));
+    }
+    if (t.State === "waiting" || t.State === "interrupted") {
+      actions.append(el("button", {onclick: () => start(t.ID, $("lobby-error"))}, "Start"));
+    }
+
+    let state = t.State;
+    if (t.Score !== undefined) {
+      state += " (" + t.Score + ")";
+    }
+    body.append(el("tr", {}, el("td", {}, t.ID), el("td", {}, t.Config.Name || ""), seats, el("td", {}, state), actions));
+  }
+}
+
+async function start(id, errors) {
+  try {
+    await api("POST", "/tables/" + id + "/start");
+    errors.textContent = "";
+  } catch (e) {
+    errors.textContent = e.message;
+  }
+  refreshTables();
+}
+
+$("create").onsubmit = async (event) => {
+  event.preventDefault();
+  const form = event.target;
+
+  const seats = form.seats.value.split(",").map(s => s.trim()).filter(s => s).map(s => {
+    if (s === "human") {
+      return {Kind: "human"};
+    }
+    if (s.startsWith("http://") || s.startsWith("https://")) {
+      return {Kind: "http", URL: s};
+    }
+    return {Kind: "strategy", Strategy: s};
+  });
+  const config = {Name: form.name.value, Seats: seats};
+  if (form.seed.value) {
+    config.Seed = parseInt(form.seed.value, 10);
+  }
+
+  try {
+    await api("POST", "/tables", config);
+    $("lobby-error").textContent = "";
+  } catch (e) {
+    $("lobby-error").textContent = e.message;
+  }
+  refreshTables();
+};
+
+//
+// Playing in a seat
+//
+
+function sit(table, seat) {
+  const scheme = location.protocol === "https:" ? "wss:" : "ws:";
+  const ws = new WebSocket(scheme + "//" + location.host + "/tables/" + table.ID + "/seats/" + seat);
+
+  game = {table: table, seat: seat, ws: ws, state: null, board: null, myTurn: false};
+  $("lobby").hidden = true;
+  $("game").hidden = false;
+  $("log").replaceChildren();
+  $("game-error").textContent = "";
+
+  ws.onmessage = (event) => receive(JSON.parse(event.data));
+  ws.onclose = () => {
+    game.myTurn = false;
+    game.closed = true;
+    render();
+  };
+  render();
+}
+
+$("leave").onclick = (event) => {
+  event.preventDefault();
+  if (game) {
+    game.ws.close();
+    game = null;
+  }
+  $("game").hidden = true;
+  $("lobby").hidden = false;
+  refreshTables();
+};
+
+function receive(message) {
+  if (message.GameState) {
+    game.state = message.GameState;
+  }
+  if (message.Board) {
+    game.board = message.Board;
+  }
+
+  switch (message.MessageType) {
+    case "StartGame":
+      $("log").replaceChildren();
+      break;
+    case "ActionRequest":
+      game.myTurn = true;
+      break;
+    case "Observation":
+      log(message.Observation.Actor, message.Observation.Action);
+      break;
+    case "Error":
+      $("game-error").textContent = message.Error;
+      break;
+  }
+  render();
+}
+
+function send(action) {
+  game.myTurn = false;
+  $("game-error").textContent = "";
+  game.ws.send(JSON.stringify(action));
+  render();
+}
+
+function playerName(p) {
+  const name = seatName(game.table.Config.Seats[p]);
+  return p === game.seat ? "You (" + name + ")" : "Player " + p + " (" + name + ")";
+}
+
+function describe(action) {
+  if (action.Play) {
+    return "played card " + action.Play.Index;
+  }
+  if (action.Discard) {
+    return "discarded card " + action.Discard.Index;
+  }
+  const hint = action.GiveInformation;
+  const named = hint.Color !== undefined ? hint.Color : hint.Value + "s";
+  return "told player " + hint.PlayerIndex + " about their " + named + " (cards " + hint.Cards.join(", ") + ")";
+}
+
+function log(actor, action) {
+  $("log").append(el("li", {}, playerName(actor) + " " + describe(action)));
+}
+
+// Whether a hint naming color or value touches card (see Rules.Touches).
+function touches(rules, hint, card) {
+  const suit = (rules.Suits && rules.Suits[card.Color]) || {ColorHints: TOUCH_MATCHING, ValueHints: TOUCH_MATCHING};
+  const touch = hint.Color !== undefined ? suit.ColorHints : suit.ValueHints;
+  if (touch === TOUCH_ALWAYS) {
+    return true;
+  }
+  if (touch === TOUCH_NEVER) {
+    return false;
+  }
+  return hint.Color !== undefined ? card.Color === hint.Color : card.Value === hint.Value;
+}
+
+function canName(rules, color) {
+  return !rules.Suits || !rules.Suits[color] || rules.Suits[color].ColorHints === TOUCH_MATCHING;
+}
+
+function hintButton(player, cards, hint, label) {
+  const touched = [];
+  cards.forEach((card, i) => {
+    if (touches(game.board.Rules, hint, card)) {
+      touched.push(i);
+    }
+  });
+  if (touched.length === 0) {
+    return null;
+  }
+  const info = Object.assign({PlayerIndex: player, Cards: touched}, hint);
+  return el("button", {onclick: () => send({GiveInformation: info})}, label);
+}
+
+function cardDiv(card) {
+  return el("div", {class: "card " + card.Color}, String(card.Value));
+}
+
+function knowledgeText(k) {
+  const colors = k.Colors.length === COLORS.length ? "?" : k.Colors.map(c => c[0]).join("");
+  const values = k.Values.length === VALUES.length ? "?" : k.Values.join("");
+  return colors + " " + values;
+}
+
+function touched(k) {
+  return k.Colors.length < COLORS.length || k.Values.length < VALUES.length;
+}
+
+function render() {
+  if (!game) {
+    return;
+  }
+
+  let status = "Seat " + game.seat + " at table " + game.table.ID + ": ";
+  if (game.closed) {
+    status += "disconnected.";
+  } else if (!game.state) {
+    status += "waiting for the game to start.";
+  } else {
+    status += game.myTurn ? "your turn!" : "waiting for the others.";
+  }
+  $("status").replaceChildren(status);
+  if (!game.state) {
+    $("status").append(" ", el("button", {onclick: () => start(game.table.ID, $("game-error"))}, "Start"));
+  }
+
+  const state = game.state, board = game.board;
+  if (!state || !board) {
+    return;
+  }
+
+  $("blue").textContent = state.BlueTokens + " / " + board.Rules.MaxBlueTokens;
+  $("red").textContent = state.RedTokens;
+  $("deck").textContent = board.DeckSize;
+  $("score").textContent = board.Score;
+
+  $("piles").replaceChildren(...COLORS.map(c => el("div", {class: "card " + c}, String(board.Piles[c] || 0))));
+  $("discards").replaceChildren(...board.Discards.map(cardDiv));
+
+  const players = $("players");
+  players.replaceChildren();
+  for (const key of Object.keys(state.OtherPlayersCards).sort()) {
+    const p = parseInt(key, 10);
+    const cards = state.OtherPlayersCards[key];
+    const knowledge = board.Knowledge[key] || [];
+
+    const hand = el("div", {class: "cards"});
+    cards.forEach((card, i) => {
+      const div = cardDiv(card);
+      if (knowledge[i]) {
+        if (touched(knowledge[i])) {
+          div.classList.add("touched");
+        }
+        div.append(el("div", {class: "possible"}, knowledgeText(knowledge[i])));
+      }
+      hand.append(div);
+    });
+
+    const section = el("div", {class: "player"}, el("h3", {}, playerName(p)), hand);
+    if (game.myTurn && state.BlueTokens > 0) {
+      const hints = el("div", {class: "hints"}, "Hint: ");
+      for (const c of COLORS) {
+        const b = canName(board.Rules, c) && hintButton(p, cards, {Color: c}, c.toLowerCase());
+        if (b) {
+          hints.append(b);
+        }
+      }
+      for (const v of VALUES) {
+        const b = hintButton(p, cards, {Value: v}, String(v));
+        if (b) {
+          hints.append(b);
+        }
+      }
+      section.append(hints);
+    }
+    players.append(section);
+  }
+
+  const mine = board.Knowledge[String(state.MyPlayerIndex)] || [];
+  $("hand").replaceChildren(...mine.map((k, i) => {
+    const color = k.Colors.length === 1 ? k.Colors[0] : "unknown";
+    const value = k.Values.length === 1 ? String(k.Values[0]) : "?";
+    const div = el("div", {class: "card " + color}, value, el("div", {class: "possible"}, knowledgeText(k)));
+    if (touched(k)) {
+      div.classList.add("touched");
+    }
+    if (game.myTurn) {
+      div.append(
+        el("button", {onclick: () => send({Play: {Index: i}})}, "Play"),
+        el("button", {onclick: () => send({Discard: {Index: i}})}, "Discard"));
+    }
+    return div;
+  }));
+}
+
+refreshTables();
+setInterval(() => {
+  if (!game) {
+    refreshTables();
+  }
+}, 2000);
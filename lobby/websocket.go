@@ -0,0 +1,349 @@
+package lobby
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The largest message we'll accept from the other end.
+const kMaxMessageSize = 1 << 20
+
+// How long a write may block before the other end is given up on. Without
+// it, a player who stops reading would hold up the game when the send
+// buffer fills.
+const kWriteTimeout = 10 * time.Second
+
+const kWebSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Conn is a WebSocket connection, implementing just enough of RFC 6455 for
+// the lobby: whole text messages, pings and closing. It may be used by one
+// reader and any number of writers at once.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	client bool // clients mask what they send, servers don't
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + kWebSocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func headerContains(h http.Header, name string, value string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Browsers let any page open a WebSocket to any site, saying which page it
+// was in the Origin header. Only pages served by the lobby itself may
+// connect, so that another site a player visits can't take their seat.
+// Other clients, such as bots, don't send an Origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// Upgrades an HTTP request to a WebSocket connection. If it fails, an error
+// response has already been sent.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != "GET" || !headerContains(r.Header, "Upgrade", "websocket") ||
+		!headerContains(r.Header, "Connection", "upgrade") || key == "" {
+		http.Error(w, "Expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, fmt.Errorf("Not a WebSocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("Unsupported WebSocket version: %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+
+	if !sameOrigin(r) {
+		http.Error(w, "Cross-origin WebSocket connections aren't allowed", http.StatusForbidden)
+		return nil, fmt.Errorf("Cross-origin WebSocket handshake from %q", r.Header.Get("Origin"))
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Can't upgrade this connection", http.StatusInternalServerError)
+		return nil, fmt.Errorf("ResponseWriter doesn't support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: conn, reader: rw.Reader}, nil
+}
+
+// Opens a WebSocket connection to a ws:// URL.
+func Dial(rawurl string) (*Conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" {
+		return nil, fmt.Errorf("Unsupported scheme %q, expected ws", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host += ":80"
+	}
+
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    u,
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket handshake failed: %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		conn.Close()
+		return nil, fmt.Errorf("WebSocket handshake failed: bad Sec-WebSocket-Accept")
+	}
+
+	return &Conn{conn: conn, reader: reader, client: true}, nil
+}
+
+// Reads the next text or binary message, answering any pings on the way.
+// Returns io.EOF once the other end has closed the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	message := []byte{}
+	started := false
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, nil)
+			c.conn.Close()
+			return nil, io.EOF
+		case opText, opBinary:
+			if started {
+				return nil, fmt.Errorf("New WebSocket message before the last one finished")
+			}
+			started = true
+		case opContinuation:
+			if !started {
+				return nil, fmt.Errorf("WebSocket continuation frame without a message")
+			}
+		default:
+			return nil, fmt.Errorf("Unknown WebSocket opcode: %d", opcode)
+		}
+
+		if len(message)+len(payload) > kMaxMessageSize {
+			return nil, fmt.Errorf("WebSocket message too large")
+		}
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	if masked == c.client {
+		return false, 0, nil, fmt.Errorf("WebSocket frame masking is wrong for this end of the connection")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if length > kMaxMessageSize {
+		return false, 0, nil, fmt.Errorf("WebSocket frame too large: %d bytes", length)
+	}
+
+	mask := make([]byte, 4)
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// Sends data as a single text message.
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// Sends v, encoded as JSON, as a text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(data)
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return fmt.Errorf("WebSocket connection is closed")
+	}
+
+	frame := []byte{0x80 | opcode}
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	if c.client {
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+		frame = append(frame, mask...)
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(kWriteTimeout))
+	if _, err := c.conn.Write(append(frame, payload...)); err != nil {
+		// part of the frame may have been sent, so nothing more can be
+		// sent after it: the connection is dropped, which the reader sees
+		c.closed = true
+		c.conn.Close()
+		return err
+	}
+	if opcode == opClose {
+		c.closed = true
+	}
+	return nil
+}
+
+// Tells the other end we're closing, and closes the connection.
+func (c *Conn) Close() error {
+	c.writeFrame(opClose, nil)
+	return c.conn.Close()
+}
@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"time"
+)
+
+var (
+	kScoreBuckets   = []float64{0, 5, 10, 15, 20, 22, 24, 25}
+	kLatencyBuckets = []float64{0.0001, 0.001, 0.01, 0.1, 0.5, 1, 5, 10}
+)
+
+// GameMetrics are the standard metrics about games and the strategies
+// playing them.
+type GameMetrics struct {
+	Games   *Counter   // by result: "won" or "lost"
+	Scores  *Histogram // final score of each game
+	Strikes *Counter   // failed plays
+
+	// Time taken by strategies to choose an action, by strategy name.
+	TurnLatency *Histogram
+
+	// Requests made by httpclient strategies, by message type.
+	RequestLatency *Histogram
+	RequestErrors  *Counter
+	RequestRetries *Counter
+}
+
+// Creates the standard metrics in r.
+func NewGameMetrics(r *Registry) *GameMetrics {
+	return &GameMetrics{
+		Games:   r.NewCounter("yanhuo_games_total", "Games played.", "result"),
+		Scores:  r.NewHistogram("yanhuo_game_score", "Final score of each game.", kScoreBuckets),
+		Strikes: r.NewCounter("yanhuo_strikes_total", "Cards misplayed."),
+		TurnLatency: r.NewHistogram("yanhuo_turn_seconds",
+			"Time taken by a strategy to choose an action.", kLatencyBuckets, "strategy"),
+		RequestLatency: r.NewHistogram("yanhuo_http_request_seconds",
+			"Latency of requests to remote strategies.", kLatencyBuckets, "message_type"),
+		RequestErrors: r.NewCounter("yanhuo_http_request_errors_total",
+			"Failed requests to remote strategies.", "message_type"),
+		RequestRetries: r.NewCounter("yanhuo_http_request_retries_total",
+			"Retried requests to remote strategies.", "message_type"),
+	}
+}
+
+//
+// Observer
+//
+
+type gameObserver struct {
+	yanhuo.BaseObserver
+	metrics *GameMetrics
+	view    *yanhuo.View
+}
+
+// Returns an Observer which records games, scores and strikes. Games played
+// at the same time each need their own observer.
+func (m *GameMetrics) Observer() yanhuo.Observer {
+	return &gameObserver{metrics: m}
+}
+
+// Scores are counted by the game's rules if the observer is given a View,
+// and by the standard rules otherwise.
+func (o *gameObserver) ObserveView(v *yanhuo.View) {
+	o.view = v
+}
+
+func (o *gameObserver) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	if !successful {
+		o.metrics.Strikes.Inc()
+	}
+}
+
+func (o *gameObserver) GameComplete(won bool, piles map[yanhuo.Color]int) {
+	if won {
+		o.metrics.Games.Inc("won")
+	} else {
+		o.metrics.Games.Inc("lost")
+	}
+	score := yanhuo.Score(piles)
+	if o.view != nil {
+		score = o.view.Score()
+	}
+	o.metrics.Scores.Observe(float64(score))
+}
+
+//
+// Strategy wrapper
+//
+
+type timedStrategy struct {
+	yanhuo.PlayerStrategy
+	name    string
+	metrics *GameMetrics
+}
+
+// Optional interfaces have to be passed through explicitly, or the engine
+// won't see them.
+
+func (s *timedStrategy) ObserveDiscard(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	if t, ok := s.PlayerStrategy.(yanhuo.TableObserver); ok {
+		t.ObserveDiscard(p, c, i)
+	}
+}
+
+func (s *timedStrategy) ObserveDraw(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	if t, ok := s.PlayerStrategy.(yanhuo.TableObserver); ok {
+		t.ObserveDraw(p, c, i)
+	}
+}
+
+func (s *timedStrategy) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	if t, ok := s.PlayerStrategy.(yanhuo.TableObserver); ok {
+		t.ObservePlay(p, c, successful)
+	}
+}
+
+func (s *timedStrategy) ObserveView(v *yanhuo.View) {
+	if vo, ok := s.PlayerStrategy.(yanhuo.ViewObserver); ok {
+		vo.ObserveView(v)
+	}
+}
+
+// Rejoining replays an action already taken, so isn't timed.
+func (s *timedStrategy) Rejoin(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int,
+	action yanhuo.Action) {
+	if r, ok := s.PlayerStrategy.(yanhuo.Rejoiner); ok {
+		r.Rejoin(myPlayerIndex, otherPlayersCards, myNumCards, blueTokens, redTokens, action)
+	}
+}
+
+func (s *timedStrategy) CheckRules(rules yanhuo.Rules) error {
+	if c, ok := s.PlayerStrategy.(yanhuo.RulesChecker); ok {
+		return c.CheckRules(rules)
+	}
+	return nil
+}
+
+type timedCheatingStrategy struct {
+	*timedStrategy
+}
+
+func (s timedCheatingStrategy) ObserveOwnCards(cards []yanhuo.Card) {
+	s.PlayerStrategy.(yanhuo.CheatingStrategy).ObserveOwnCards(cards)
+}
+
+// Returns a strategy which plays exactly like s, recording how long each
+// of its turns takes under the given name.
+func (m *GameMetrics) WrapStrategy(name string, s yanhuo.PlayerStrategy) yanhuo.PlayerStrategy {
+	timed := &timedStrategy{PlayerStrategy: s, name: name, metrics: m}
+	if _, ok := s.(yanhuo.CheatingStrategy); ok {
+		return timedCheatingStrategy{timed}
+	}
+	return timed
+}
+
+func (s *timedStrategy) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	start := time.Now()
+	defer func() {
+		s.metrics.TurnLatency.Observe(time.Since(start).Seconds(), s.name)
+	}()
+	return s.PlayerStrategy.Act(myPlayerIndex, otherPlayersCards, myNumCards, blueTokens, redTokens)
+}
+
+//
+// httpclient.RequestObserver
+//
+
+// Records a request made by an httpclient strategy. Pass the GameMetrics to
+// HttpClientStrategy.SetRequestObserver.
+func (m *GameMetrics) ObserveRequest(messageType string, latency time.Duration, attempt int, err error) {
+	m.RequestLatency.Observe(latency.Seconds(), messageType)
+	if err != nil {
+		m.RequestErrors.Inc(messageType)
+	}
+	if attempt > 0 {
+		m.RequestRetries.Inc(messageType)
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/alwaysplay"
+	"github.com/mrjones/yanhuo/strategies/conventions"
+	"github.com/mrjones/yanhuo/strategies/hatguessing"
+	"github.com/mrjones/yanhuo/strategies/heuristic"
+	"github.com/mrjones/yanhuo/strategies/httpclient"
+	"github.com/mrjones/yanhuo/strategies/oracle"
+
+	"testing"
+)
+
+var _ httpclient.RequestObserver = &GameMetrics{}
+
+func TestGameMetrics(t *testing.T) {
+	m := NewGameMetrics(NewRegistry())
+
+	wrapped := []yanhuo.PlayerStrategy{
+		m.WrapStrategy("heuristic", heuristic.NewHeuristicStrategy(1)),
+		m.WrapStrategy("heuristic", heuristic.NewHeuristicStrategy(2)),
+	}
+	game, err := yanhuo.InitializeGameWithOptions(wrapped, []yanhuo.Observer{m.Observer()}, yanhuo.GameOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	// The wrapped strategies must still see the table, or they would play
+	// differently.
+	plain, err := yanhuo.InitializeGameWithOptions(
+		[]yanhuo.PlayerStrategy{heuristic.NewHeuristicStrategy(1), heuristic.NewHeuristicStrategy(2)},
+		nil, yanhuo.GameOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain.Play()
+	if plain.Record().Score != record.Score || len(plain.Record().Turns) != len(record.Turns) {
+		t.Errorf("Wrapping changed the game: scored %d in %d turns instead of %d in %d",
+			record.Score, len(record.Turns), plain.Record().Score, len(plain.Record().Turns))
+	}
+
+	if games := m.Games.Value("won") + m.Games.Value("lost"); games != 1 {
+		t.Errorf("Expected 1 game, got %v", games)
+	}
+	if m.Scores.Count() != 1 {
+		t.Errorf("Expected 1 score, got %d", m.Scores.Count())
+	}
+	if turns := m.TurnLatency.Count("heuristic"); turns != uint64(len(record.Turns)) {
+		t.Errorf("Expected %d turns timed, got %d", len(record.Turns), turns)
+	}
+}
+
+func TestScoresByRules(t *testing.T) {
+	m := NewGameMetrics(NewRegistry())
+
+	rules := yanhuo.StandardRules()
+	rules.StrikeOutScoresZero = true
+	game, err := yanhuo.InitializeGameWithOptions(
+		[]yanhuo.PlayerStrategy{&alwaysplay.AlwaysPlayFirstCardStrategy{}, &alwaysplay.AlwaysPlayFirstCardStrategy{}},
+		[]yanhuo.Observer{m.Observer()}, yanhuo.GameOptions{Seed: 1, Rules: &rules})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	if score := game.Record().Score; score != 0 || m.Scores.Sum() != 0 {
+		t.Errorf("Expected a strike-out to be recorded as 0, got %d and %v", score, m.Scores.Sum())
+	}
+}
+
+func TestWrapStrategyRejoins(t *testing.T) {
+	m := NewGameMetrics(NewRegistry())
+	for _, test := range []struct {
+		name string
+		new  func() yanhuo.PlayerStrategy
+	}{
+		{"hatguessing", func() yanhuo.PlayerStrategy { return hatguessing.NewHatGuessingStrategy() }},
+		// forgets which of its cards it played or discarded, if not told
+		{"conventions", func() yanhuo.PlayerStrategy { return conventions.NewConventionStrategy("conventions") }},
+	} {
+		players := func() []yanhuo.PlayerStrategy {
+			out := []yanhuo.PlayerStrategy{}
+			for i := 0; i < 4; i++ {
+				out = append(out, m.WrapStrategy(test.name, test.new()))
+			}
+			return out
+		}
+
+		game, err := yanhuo.InitializeGameWithOptions(players(), nil, yanhuo.GameOptions{Seed: 11})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+		record := game.Record()
+
+		for turn := 1; turn < len(record.Turns); turn += 4 {
+			rewound, err := yanhuo.Rewind(record, turn, players(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rewound.Play()
+			continued := rewound.Record()
+
+			if continued.Score != record.Score || len(continued.Turns) != len(record.Turns) {
+				t.Errorf("%s continued from turn %d scored %d in %d turns; the original scored %d in %d",
+					test.name, turn, continued.Score, len(continued.Turns), record.Score, len(record.Turns))
+			}
+		}
+	}
+}
+
+func TestWrapStrategyChecksRules(t *testing.T) {
+	m := NewGameMetrics(NewRegistry())
+	rules := yanhuo.StandardRules()
+	rules.Suits = map[yanhuo.Color]yanhuo.SuitRules{yanhuo.RED: {ColorHints: yanhuo.TouchAlways}}
+
+	players := []yanhuo.PlayerStrategy{}
+	for i := 0; i < 3; i++ {
+		players = append(players, m.WrapStrategy("conventions", conventions.NewConventionStrategy("conventions")))
+	}
+	if _, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Wrapped conventions should refuse variant suits")
+	}
+}
+
+func TestWrapStrategyKeepsCheating(t *testing.T) {
+	m := NewGameMetrics(NewRegistry())
+	if _, ok := m.WrapStrategy("oracle", oracle.NewOracleStrategy()).(yanhuo.CheatingStrategy); !ok {
+		t.Errorf("Wrapped oracle should still be a CheatingStrategy")
+	}
+	if _, ok := m.WrapStrategy("heuristic", heuristic.NewHeuristicStrategy(1)).(yanhuo.CheatingStrategy); ok {
+		t.Errorf("Wrapped heuristic should not be a CheatingStrategy")
+	}
+}
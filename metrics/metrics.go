@@ -0,0 +1,267 @@
+// Package metrics keeps counters and histograms about games being played,
+// and serves them over HTTP in the Prometheus text exposition format, so
+// that long-running tournaments and hosted strategies can be monitored.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds a set of metrics, and is an http.Handler which serves
+// their current values. All methods are safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+type metric interface {
+	write(buffer *bytes.Buffer)
+}
+
+// Counter is a value which only goes up, e.g. the number of games played.
+// It may be split into series by label values, which must be given in the
+// same order as the labels the counter was created with.
+type Counter struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+// Histogram counts observed values (e.g. latencies) in buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // one per bucket, not cumulative
+	count       uint64
+	sum         float64
+}
+
+// Creates a counter named name, split by the given labels.
+func (r *Registry) NewCounter(name string, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, labels: labels, series: map[string]*counterSeries{}}
+	r.add(c)
+	return c
+}
+
+// Creates a histogram named name, with the given bucket upper bounds, split
+// by the given labels.
+func (r *Registry) NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	h := &Histogram{name: name, help: help, labels: labels, buckets: buckets, series: map[string]*histogramSeries{}}
+	r.add(h)
+	return h
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	checkLabels(c.name, c.labels, labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := strings.Join(labelValues, "\x00")
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labelValues: append([]string{}, labelValues...)}
+		c.series[key] = s
+	}
+	s.value += delta
+}
+
+// Returns the counter's value for the given label values.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.series[strings.Join(labelValues, "\x00")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	checkLabels(h.name, h.labels, labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := strings.Join(labelValues, "\x00")
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: append([]string{}, labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Returns the number of values observed for the given label values.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[strings.Join(labelValues, "\x00")]; ok {
+		return s.count
+	}
+	return 0
+}
+
+// Returns the sum of the values observed for the given label values.
+func (h *Histogram) Sum(labelValues ...string) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[strings.Join(labelValues, "\x00")]; ok {
+		return s.sum
+	}
+	return 0
+}
+
+func checkLabels(name string, labels []string, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("Metric %s has %d labels, got %d values", name, len(labels), len(values)))
+	}
+}
+
+//
+// Text exposition format
+//
+
+// Writes every metric in the text exposition format.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric{}, r.metrics...)
+	r.mu.Unlock()
+
+	var buffer bytes.Buffer
+	for _, m := range metrics {
+		m.write(&buffer)
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteText(w)
+}
+
+func writeHeader(buffer *bytes.Buffer, name string, help string, kind string) {
+	buffer.WriteString(fmt.Sprintf("# HELP %s %s\n", name, help))
+	buffer.WriteString(fmt.Sprintf("# TYPE %s %s\n", name, kind))
+}
+
+var kLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Formats label pairs as {a="x",b="y"}, or "" if there are none.
+func formatLabels(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := []string{}
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, kLabelEscaper.Replace(values[i])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Counter) write(buffer *bytes.Buffer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(buffer, c.name, c.help, "counter")
+	keys := map[string]bool{}
+	for k := range c.series {
+		keys[k] = true
+	}
+	for _, k := range sortedKeys(keys) {
+		s := c.series[k]
+		buffer.WriteString(fmt.Sprintf("%s%s %s\n",
+			c.name, formatLabels(c.labels, s.labelValues), formatValue(s.value)))
+	}
+}
+
+func (h *Histogram) write(buffer *bytes.Buffer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(buffer, h.name, h.help, "histogram")
+	keys := map[string]bool{}
+	for k := range h.series {
+		keys[k] = true
+	}
+	labels := append(append([]string{}, h.labels...), "le")
+	for _, k := range sortedKeys(keys) {
+		s := h.series[k]
+		cumulative := uint64(0)
+		bounds := append(append([]float64{}, h.buckets...), math.Inf(1))
+		for i, bound := range bounds {
+			if i < len(s.counts) {
+				cumulative += s.counts[i]
+			} else {
+				cumulative = s.count
+			}
+			values := append(append([]string{}, s.labelValues...), formatValue(bound))
+			buffer.WriteString(fmt.Sprintf("%s_bucket%s %d\n", h.name, formatLabels(labels, values), cumulative))
+		}
+		buffer.WriteString(fmt.Sprintf("%s_sum%s %s\n", h.name, formatLabels(h.labels, s.labelValues), formatValue(s.sum)))
+		buffer.WriteString(fmt.Sprintf("%s_count%s %d\n", h.name, formatLabels(h.labels, s.labelValues), s.count))
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("things_total", "Things.", "kind")
+	h := r.NewHistogram("sizes", "Sizes.", []float64{10, 1})
+
+	c.Inc("a")
+	c.Add(2, `say "hi"`)
+	h.Observe(0.5)
+	h.Observe(5)
+	h.Observe(50)
+
+	var out bytes.Buffer
+	if err := r.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"# HELP things_total Things.",
+		"# TYPE things_total counter",
+		`things_total{kind="a"} 1`,
+		`things_total{kind="say \"hi\""} 2`,
+		"# HELP sizes Sizes.",
+		"# TYPE sizes histogram",
+		`sizes_bucket{le="1"} 1`,
+		`sizes_bucket{le="10"} 2`,
+		`sizes_bucket{le="+Inf"} 3`,
+		"sizes_sum 55.5",
+		"sizes_count 3",
+		"",
+	}, "\n")
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("games_total", "Games.").Inc()
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	body.ReadFrom(resp.Body)
+	if !strings.Contains(body.String(), "games_total 1\n") {
+		t.Errorf("Unexpected response:\n%s", body.String())
+	}
+}
@@ -0,0 +1,251 @@
+// Package report renders recorded games for people to read.
+package report
+
+import (
+	"github.com/mrjones/yanhuo/analysis"
+	"github.com/mrjones/yanhuo/core"
+
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Report describes one recorded game to be rendered.
+type Report struct {
+	Title  string
+	Record *yanhuo.GameRecord
+
+	// Shown alongside the turns they refer to, e.g. from analysis.Analyze.
+	Annotations []analysis.Annotation
+}
+
+// Writes the game as a single self-contained HTML page, which steps through
+// it one turn at a time.
+func (r *Report) WriteHTML(w io.Writer) error {
+	tracker := yanhuo.NewBoardTracker()
+	if err := yanhuo.Replay(r.Record, []yanhuo.Observer{tracker}); err != nil {
+		return err
+	}
+
+	page := htmlPage{Title: r.Title, Score: r.Record.Score, Won: r.Record.Won}
+	if page.Title == "" {
+		page.Title = fmt.Sprintf("Game %d", r.Record.Seed)
+	}
+
+	for i, board := range tracker.Boards {
+		var turn *yanhuo.BoardTurn
+		if i < len(tracker.Turns) {
+			turn = &tracker.Turns[i]
+		}
+		frame := newFrame(i, board, turn)
+		for _, a := range r.Annotations {
+			if a.Turn == i {
+				frame.Notes = append(frame.Notes, fmt.Sprintf("%s: %s", a.Kind, a.Message))
+			}
+		}
+		page.Frames = append(page.Frames, frame)
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+type htmlPage struct {
+	Title  string
+	Score  int
+	Won    bool
+	Frames []htmlFrame
+}
+
+// One step of the report: the board before a turn, and what happened on it.
+// The last frame shows the board at the end of the game.
+type htmlFrame struct {
+	Turn        int
+	Description string
+	Notes       []string
+	Active      int // the player taking the turn, or -1 after the game
+	Hands       [][]htmlCard
+	Piles       []htmlCard
+	Discards    [][]htmlCard // grouped by color
+	BlueTokens  int
+	RedTokens   int
+	DeckSize    int
+	MaxScore    int
+	Pace        int
+}
+
+type htmlCard struct {
+	Class     string // the card's color, for styling
+	Label     string
+	Knowledge string // what the holder knows, for cards in hands
+	Touched   bool   // whether any hint has referred to the card
+	Selected  bool   // whether this turn's action refers to the card
+}
+
+func colorClass(c yanhuo.Color) string {
+	text, err := c.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(string(text))
+}
+
+func newFrame(i int, board yanhuo.Board, turn *yanhuo.BoardTurn) htmlFrame {
+	frame := htmlFrame{
+		Turn:       i + 1,
+		Active:     -1,
+		BlueTokens: board.BlueTokens,
+		RedTokens:  board.RedTokens,
+		DeckSize:   board.DeckSize,
+		MaxScore:   board.MaxScore(),
+		Pace:       board.Pace(),
+	}
+
+	selected := map[yanhuo.PlayerIndex]map[yanhuo.HandIndex]bool{}
+	if turn != nil {
+		frame.Active = int(turn.Player)
+		frame.Description = describe(turn)
+		selected[turn.Player] = map[yanhuo.HandIndex]bool{}
+		switch a := turn.Action; {
+		case a.GiveInformation != nil:
+			selected[a.GiveInformation.PlayerIndex] = map[yanhuo.HandIndex]bool{}
+			for _, j := range a.GiveInformation.Cards {
+				selected[a.GiveInformation.PlayerIndex][j] = true
+			}
+		case a.Discard != nil:
+			selected[turn.Player][a.Discard.Index] = true
+		case a.Play != nil:
+			selected[turn.Player][a.Play.Index] = true
+		}
+	} else {
+		frame.Description = "Game over"
+	}
+
+	for p, hand := range board.Hands {
+		cards := []htmlCard{}
+		for j, c := range hand {
+			k := board.Knowledge[p][j]
+			cards = append(cards, htmlCard{
+				Class:     colorClass(c.Color),
+				Label:     c.String(),
+				Knowledge: k.String(),
+				Touched:   k.Touched,
+				Selected:  selected[yanhuo.PlayerIndex(p)][yanhuo.HandIndex(j)],
+			})
+		}
+		frame.Hands = append(frame.Hands, cards)
+	}
+
+	for _, c := range yanhuo.ALL_COLORS {
+		pile := htmlCard{Class: colorClass(c), Label: "-"}
+		if height := board.Piles[c]; height > 0 {
+			pile.Label = yanhuo.Card{Color: c, Value: yanhuo.Value(height)}.String()
+		}
+		frame.Piles = append(frame.Piles, pile)
+
+		discards := []htmlCard{}
+		for _, v := range yanhuo.ALL_VALUES {
+			for _, d := range board.Discards {
+				if d.Color == c && d.Value == v {
+					discards = append(discards, htmlCard{Class: colorClass(c), Label: d.String()})
+				}
+			}
+		}
+		frame.Discards = append(frame.Discards, discards)
+	}
+
+	return frame
+}
+
+func describe(turn *yanhuo.BoardTurn) string {
+	a := turn.Action
+	switch {
+	case a.GiveInformation != nil:
+		hint := a.GiveInformation
+		what := ""
+		if hint.Color != nil {
+			what = colorClass(hint.Color.Color)
+		} else {
+			what = fmt.Sprintf("%ds", hint.Value.Value)
+		}
+		return fmt.Sprintf("Player %d tells player %d about their %s (cards %v)",
+			turn.Player, hint.PlayerIndex, what, hint.Cards)
+	case a.Discard != nil:
+		return fmt.Sprintf("Player %d discards %s", turn.Player, turn.Card)
+	case a.Play != nil:
+		if turn.Successful {
+			return fmt.Sprintf("Player %d plays %s", turn.Player, turn.Card)
+		}
+		return fmt.Sprintf("Player %d misplays %s", turn.Player, turn.Card)
+	default:
+		return "Invalid action"
+	}
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; background: #f4f4f0; margin: 2em; }
+.frame { display: none; }
+.frame.current { display: block; }
+.player { margin: 0.5em 0; padding: 0.3em; }
+.player.active { background: #e4e4a0; }
+.card { display: inline-block; width: 2.6em; margin: 0.1em; padding: 0.2em; text-align: center;
+  border: 2px solid #444; border-radius: 4px; font-weight: bold; vertical-align: top; }
+.card .knowledge { display: block; font-size: 70%; font-weight: normal; }
+.card.touched { box-shadow: 0 0 0 2px #333; }
+.card.selected { border: 3px dashed #000; }
+.white { background: #ffffff; } .red { background: #f08080; } .blue { background: #80a8f0; }
+.yellow { background: #f0e070; } .green { background: #80d080; }
+.description { font-size: 120%; margin: 1em 0; }
+.note { color: #a00000; margin: 0.3em 0; }
+.tokens span { margin-right: 1.5em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Score: {{.Score}}{{if .Won}} (won){{end}}</p>
+<p>
+<button id="prev" onclick="show(current - 1)">&larr; Prev</button>
+<button id="next" onclick="show(current + 1)">Next &rarr;</button>
+<span id="position"></span>
+</p>
+{{range .Frames}}{{$frame := .}}
+<div class="frame">
+<div class="description">Turn {{.Turn}}: {{.Description}}</div>
+{{range .Notes}}<div class="note">&#9888; {{.}}</div>
+{{end}}<div class="tokens"><span>Blue tokens: {{.BlueTokens}}</span><span>Red tokens: {{.RedTokens}}</span><span>Deck: {{.DeckSize}}</span><span>Max score: {{.MaxScore}}</span><span>Pace: {{.Pace}}</span></div>
+<h3>Hands</h3>
+{{range $p, $hand := .Hands}}
+<div class="player{{if eq $p $frame.Active}} active{{end}}">Player {{$p}}:
+{{range $hand}}<span class="card {{.Class}}{{if .Touched}} touched{{end}}{{if .Selected}} selected{{end}}">{{.Label}}<span class="knowledge">{{.Knowledge}}</span></span>{{end}}
+</div>
+{{end}}
+<h3>Piles</h3>
+<div>{{range .Piles}}<span class="card {{.Class}}">{{.Label}}</span>{{end}}</div>
+<h3>Discards</h3>
+{{range .Discards}}{{if .}}<div>{{range .}}<span class="card {{.Class}}">{{.Label}}</span>{{end}}</div>{{end}}{{end}}
+</div>
+{{end}}
+<script>
+var frames = document.getElementsByClassName("frame");
+var current = 0;
+function show(i) {
+  if (i < 0 || i >= frames.length) { return; }
+  frames[current].className = "frame";
+  current = i;
+  frames[current].className = "frame current";
+  document.getElementById("position").textContent = (current + 1) + " / " + frames.length;
+}
+document.addEventListener("keydown", function(e) {
+  if (e.key == "ArrowLeft") { show(current - 1); }
+  if (e.key == "ArrowRight") { show(current + 1); }
+});
+show(0);
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,74 @@
+package report
+
+import (
+	"github.com/mrjones/yanhuo/analysis"
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/heuristic"
+
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	game, err := yanhuo.InitializeGameWithOptions(
+		[]yanhuo.PlayerStrategy{heuristic.NewHeuristicStrategy(1), heuristic.NewHeuristicStrategy(2)},
+		nil,
+		yanhuo.GameOptions{Seed: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	var out bytes.Buffer
+	r := &Report{Title: "Test <game>", Record: record}
+	if err := r.WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+
+	if frames := strings.Count(html, `<div class="frame">`); frames != len(record.Turns)+1 {
+		t.Errorf("Expected %d frames, got %d", len(record.Turns)+1, frames)
+	}
+	if !strings.Contains(html, "Test &lt;game&gt;") {
+		t.Errorf("Title should be escaped")
+	}
+	for _, external := range []string{"src=", "href="} {
+		if strings.Contains(html, external) {
+			t.Errorf("Report should be self-contained, but contains %q", external)
+		}
+	}
+}
+
+func TestWriteHTMLShowsAnnotations(t *testing.T) {
+	game, err := yanhuo.InitializeGameWithOptions(
+		[]yanhuo.PlayerStrategy{heuristic.NewHeuristicStrategy(1), heuristic.NewHeuristicStrategy(2)},
+		nil,
+		yanhuo.GameOptions{Seed: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	var out bytes.Buffer
+	r := &Report{
+		Record: game.Record(),
+		Annotations: []analysis.Annotation{
+			{Turn: 1, Kind: analysis.UselessHint, Message: "told player 0 nothing"},
+		},
+	}
+	if err := r.WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "useless hint: told player 0 nothing") {
+		t.Errorf("Annotation should be shown")
+	}
+}
+
+func TestWriteHTMLRejectsBadRecords(t *testing.T) {
+	r := &Report{Record: &yanhuo.GameRecord{NumPlayers: 2}}
+	if err := r.WriteHTML(&bytes.Buffer{}); err == nil {
+		t.Errorf("Expected an error for an empty record")
+	}
+}
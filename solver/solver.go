@@ -0,0 +1,410 @@
+// Package solver computes the best score achievable from a particular deal,
+// by searching the game played with perfect information: every player knows
+// every card, including their own and the order of the deck.
+//
+// No real strategy can do better than this, so it's a useful yardstick for
+// how much of a deal's potential a strategy realized.
+package solver
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// The number of positions to explore before giving up on proving that the
+// best score found is optimal.
+const kDefaultMaxNodes = 200000
+
+type Result struct {
+	// The best score found.
+	Score int
+
+	// Whether Score is known to be the best possible. If not, the search
+	// ran out of time and the best possible score is between Score and
+	// UpperBound.
+	Exact      bool
+	UpperBound int
+}
+
+// Returns the best score achievable when dealing deck (in order) to
+// numPlayers players, with startingPlayer taking the first turn, playing by
+// the standard rules.
+func BestScore(deck []yanhuo.Card, numPlayers int, startingPlayer yanhuo.PlayerIndex) (Result, error) {
+	return BestScoreWithLimit(deck, numPlayers, startingPlayer, kDefaultMaxNodes)
+}
+
+// Returns the best score achievable for the deal of a recorded game, by the
+// rules it was played with.
+func BestScoreForRecord(record *yanhuo.GameRecord) (Result, error) {
+	return BestScoreWithRules(record.Deck, record.NumPlayers, record.StartingPlayer, record.PlayedRules(), kDefaultMaxNodes)
+}
+
+// Like BestScore, but explores at most maxNodes positions.
+func BestScoreWithLimit(deck []yanhuo.Card, numPlayers int, startingPlayer yanhuo.PlayerIndex, maxNodes int) (Result, error) {
+	return BestScoreWithRules(deck, numPlayers, startingPlayer, yanhuo.StandardRules(), maxNodes)
+}
+
+// Like BestScoreWithLimit, but playing by the given rules.
+func BestScoreWithRules(deck []yanhuo.Card, numPlayers int, startingPlayer yanhuo.PlayerIndex, rules yanhuo.Rules, maxNodes int) (Result, error) {
+	handSize, err := rules.HandSize(numPlayers)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(deck) < numPlayers*handSize {
+		return Result{}, fmt.Errorf("Deck of %d cards is too small to deal %d hands of %d cards",
+			len(deck), numPlayers, handSize)
+	}
+
+	st := &state{
+		hands:   make([][]yanhuo.Card, numPlayers),
+		piles:   make([]int, len(yanhuo.ALL_COLORS)),
+		blue:    rules.MaxBlueTokens,
+		red:     rules.RedTokens,
+		current: int(startingPlayer),
+	}
+	for c := 0; c < handSize; c++ {
+		for p := 0; p < numPlayers; p++ {
+			st.hands[p] = append(st.hands[p], deck[st.deckPos])
+			st.deckPos++
+		}
+	}
+
+	s := &search{
+		deck:     deck,
+		rules:    rules,
+		maxNodes: maxNodes,
+		visited:  make(map[string]bool),
+	}
+	bound := s.upperBound(st)
+	s.best = s.greedy(st)
+	s.explore(st)
+
+	result := Result{Score: s.best, UpperBound: bound}
+	if !s.aborted || s.best == bound {
+		result.Exact = true
+		result.UpperBound = s.best
+	}
+	return result, nil
+}
+
+type state struct {
+	hands      [][]yanhuo.Card
+	deckPos    int
+	piles      []int
+	blue       int
+	red        int
+	current    int
+	finalTurns int
+	score      int
+}
+
+func (st *state) clone() *state {
+	out := *st
+	out.hands = make([][]yanhuo.Card, len(st.hands))
+	for p, hand := range st.hands {
+		out.hands[p] = append([]yanhuo.Card{}, hand...)
+	}
+	out.piles = append([]int{}, st.piles...)
+	return &out
+}
+
+// A key identifying positions which have the same future. The order of the
+// cards within a hand doesn't matter when everybody can see them.
+func (st *state) key() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%d/%d/%d/%d/%d/%v", st.deckPos, st.blue, st.red, st.current, st.finalTurns, st.piles)
+	for _, hand := range st.hands {
+		codes := make([]int, len(hand))
+		for i, c := range hand {
+			codes[i] = int(c.Color)*16 + int(c.Value)
+		}
+		sort.Ints(codes)
+		fmt.Fprintf(&buffer, "|%v", codes)
+	}
+	return buffer.String()
+}
+
+type search struct {
+	deck     []yanhuo.Card
+	rules    yanhuo.Rules
+	maxNodes int
+	nodes    int
+	aborted  bool
+	best     int
+	visited  map[string]bool
+}
+
+func (s *search) explore(st *state) {
+	if st.score > s.best {
+		s.best = st.score
+	}
+
+	if s.finished(st) || s.upperBound(st) <= s.best {
+		return
+	}
+
+	key := st.key()
+	if s.visited[key] {
+		return
+	}
+	s.visited[key] = true
+
+	s.nodes++
+	if s.nodes > s.maxNodes {
+		s.aborted = true
+		return
+	}
+
+	for _, move := range s.moves(st) {
+		s.explore(s.apply(st, move))
+		if s.aborted {
+			return
+		}
+	}
+}
+
+// Plays the deal out with a simple policy, to get a good score to beat
+// before starting the search: play the lowest playable card, otherwise
+// discard a useless card, otherwise stall with a hint while someone can
+// play, otherwise make the safest discard.
+func (s *search) greedy(st *state) int {
+	for !s.finished(st) {
+		moves := s.moves(st)
+		discard := moves[len(moves)-1]
+		for _, m := range moves {
+			if m.moveType == kDiscard {
+				discard = m
+				break
+			}
+		}
+		rank := s.discardRank(st, st.hands[st.current][discard.index])
+
+		choice := discard
+		switch {
+		case moves[0].moveType == kPlay:
+			choice = moves[0]
+		case rank == 0 && st.blue < s.rules.MaxBlueTokens:
+			choice = discard
+		case st.blue > 0 && (st.blue == s.rules.MaxBlueTokens || s.someoneCanPlay(st)):
+			choice = move{kHint, 0}
+		}
+		st = s.apply(st, choice)
+	}
+	return st.score
+}
+
+func (s *search) someoneCanPlay(st *state) bool {
+	for _, hand := range st.hands {
+		for _, c := range hand {
+			if int(c.Value) == st.piles[c.Color]+1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *search) finished(st *state) bool {
+	return st.score == len(st.piles)*5 || st.red == 0 || st.finalTurns == len(st.hands)
+}
+
+// An optimistic estimate of the final score: every card which can still be
+// played gets played, as long as there are enough turns left to do so. Every
+// play draws a card while there are any left, so once a card is drawn there
+// are only so many turns left to play it and everything above it.
+func (s *search) upperBound(st *state) int {
+	inHand := map[yanhuo.Card]bool{}
+	for _, hand := range st.hands {
+		for _, c := range hand {
+			inHand[c] = true
+		}
+	}
+	firstDrawn := map[yanhuo.Card]int{}
+	for j := len(s.deck) - 1; j >= st.deckPos; j-- {
+		firstDrawn[s.deck[j]] = j
+	}
+
+	turnsLeft := len(s.deck) - st.deckPos + len(st.hands) - st.finalTurns
+
+	playable := 0
+	for color, height := range st.piles {
+		// how many turns are left after each value is drawn
+		limits := []int{}
+		top := height
+		for v := height + 1; v <= 5; v++ {
+			c := yanhuo.Card{Color: yanhuo.Color(color), Value: yanhuo.Value(v)}
+			if inHand[c] {
+				limits = append(limits, turnsLeft)
+			} else if j, ok := firstDrawn[c]; ok {
+				limits = append(limits, len(s.deck)-j-1+len(st.hands))
+			} else {
+				break
+			}
+
+			reachable := true
+			for i, limit := range limits {
+				if len(limits)-i > limit {
+					reachable = false
+				}
+			}
+			if !reachable {
+				break
+			}
+			top = v
+		}
+		playable += top - height
+	}
+
+	if turnsLeft < playable {
+		playable = turnsLeft
+	}
+	return st.score + playable
+}
+
+type moveType int
+
+const (
+	kPlay moveType = iota
+	kHint
+	kDiscard
+)
+
+type move struct {
+	moveType moveType
+	index    int
+}
+
+// Returns the moves worth considering, most promising first. Misplays are
+// never better than discarding the same card, so they're left out.
+func (s *search) moves(st *state) []move {
+	hand := st.hands[st.current]
+
+	plays := []move{}
+	discards := []move{}
+	discardRank := map[int]int{}
+	seen := map[yanhuo.Card]bool{}
+	for i, c := range hand {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+
+		if int(c.Value) == st.piles[c.Color]+1 {
+			plays = append(plays, move{kPlay, i})
+		}
+		discards = append(discards, move{kDiscard, i})
+		discardRank[i] = s.discardRank(st, c)
+	}
+
+	sort.SliceStable(plays, func(a, b int) bool {
+		return hand[plays[a].index].Value < hand[plays[b].index].Value
+	})
+	sort.SliceStable(discards, func(a, b int) bool {
+		return discardRank[discards[a].index] < discardRank[discards[b].index]
+	})
+
+	// Discarding a useless card is as good as a hint for passing the turn,
+	// and gets a blue token back.
+	moves := plays
+	for len(discards) > 0 && discardRank[discards[0].index] == 0 && st.blue < s.rules.MaxBlueTokens {
+		moves = append(moves, discards[0])
+		discards = discards[1:]
+	}
+	if st.blue > 0 {
+		moves = append(moves, move{kHint, 0})
+	}
+	return append(moves, discards...)
+}
+
+// Orders discards: useless cards, then cards with another copy in
+// someone's hand, then cards with another copy still in the deck, then the
+// last copies, with the cards needed soonest last.
+func (s *search) discardRank(st *state, c yanhuo.Card) int {
+	if s.dead(st, c) {
+		return 0
+	}
+
+	held := 0
+	for _, hand := range st.hands {
+		for _, other := range hand {
+			if other == c {
+				held++
+			}
+		}
+	}
+	if held > 1 {
+		return 1
+	}
+
+	for _, other := range s.deck[st.deckPos:] {
+		if other == c {
+			return 2
+		}
+	}
+	return 3 + (5 - int(c.Value))
+}
+
+// Whether c has already been played, or some card below it is gone.
+func (s *search) dead(st *state, c yanhuo.Card) bool {
+	if int(c.Value) <= st.piles[c.Color] {
+		return true
+	}
+
+	remaining := map[yanhuo.Card]bool{}
+	for _, hand := range st.hands {
+		for _, other := range hand {
+			remaining[other] = true
+		}
+	}
+	for _, other := range s.deck[st.deckPos:] {
+		remaining[other] = true
+	}
+	for v := st.piles[c.Color] + 1; v < int(c.Value); v++ {
+		if !remaining[yanhuo.Card{Color: c.Color, Value: yanhuo.Value(v)}] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *search) apply(st *state, m move) *state {
+	next := st.clone()
+	deckWasEmpty := next.deckPos == len(s.deck)
+
+	switch m.moveType {
+	case kPlay:
+		c := next.hands[next.current][m.index]
+		next.piles[c.Color]++
+		next.score++
+		if c.Value == 5 && s.rules.RefundBlueTokenOnFive && next.blue < s.rules.MaxBlueTokens {
+			next.blue++
+		}
+		s.drawReplacement(next, m.index)
+	case kDiscard:
+		if next.blue < s.rules.MaxBlueTokens {
+			next.blue++
+		}
+		s.drawReplacement(next, m.index)
+	case kHint:
+		next.blue--
+	}
+
+	if deckWasEmpty {
+		next.finalTurns++
+	}
+	next.current = (next.current + 1) % len(next.hands)
+	return next
+}
+
+func (s *search) drawReplacement(st *state, i int) {
+	hand := st.hands[st.current]
+	if st.deckPos < len(s.deck) {
+		hand[i] = s.deck[st.deckPos]
+		st.deckPos++
+	} else {
+		st.hands[st.current] = append(hand[:i], hand[i+1:]...)
+	}
+}
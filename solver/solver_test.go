@@ -0,0 +1,49 @@
+package solver
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"testing"
+)
+
+func TestSortedDeckScoresPerfectly(t *testing.T) {
+	result, err := BestScore(yanhuo.NewDeck(), 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Score != 25 || !result.Exact {
+		t.Errorf("Expected an exact 25 for a sorted deck, got %+v", result)
+	}
+}
+
+func TestLateOnesLimitScore(t *testing.T) {
+	// Put every red 1 at the bottom of the deck. With two players there are
+	// only four turns left once the first of them is drawn, so at most four
+	// red cards can be played.
+	deck := []yanhuo.Card{}
+	ones := []yanhuo.Card{}
+	for _, c := range yanhuo.NewDeck() {
+		if c.Color == yanhuo.RED && c.Value == 1 {
+			ones = append(ones, c)
+		} else {
+			deck = append(deck, c)
+		}
+	}
+	deck = append(deck, ones...)
+
+	result, err := BestScore(deck, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Score > 24 || result.UpperBound > 24 {
+		t.Errorf("Red 1s arrive too late to finish red, but got %+v", result)
+	}
+}
+
+func TestInvalidPlayerCount(t *testing.T) {
+	if _, err := BestScore(yanhuo.NewDeck(), 7, 0); err == nil {
+		t.Errorf("Expected an error for 7 players")
+	}
+}
@@ -0,0 +1,715 @@
+// Package conventions implements a bot which follows the common beginner
+// conventions of "H-group" style play:
+//
+//   - Cards are drawn into the newest slot; a player's chop is their oldest
+//     card which hasn't been touched by a hint, and that's what they discard.
+//   - A hint's focus is the chop, if the hint newly touched it, or otherwise
+//     the newest newly touched card.
+//   - A value hint of 5 or 2 focused on the chop saves the card. Otherwise a
+//     hint is a play hint if its focus could be playable, and a save hint if
+//     it is on the chop and couldn't be.
+//   - A play hint on a card which is one away from playable, where the
+//     connecting card isn't in anyone else's hand, means the connecting card
+//     is on our finesse position (newest untouched card), so we blind play it.
+//
+// Everything is derived from the actions observed during the game, and every
+// decision comes with a reason (see LastReason) to help debug the
+// conventions against recorded games.
+//
+// The conventions assume hints touch cards by the standard rules, so this bot
+// refuses to play variants with suits which hints touch differently (see
+// CheckRules).
+package conventions
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"fmt"
+	"log"
+)
+
+type clueKind int
+
+const (
+	kNoNewInformation clueKind = iota
+	kPlayClue
+	kSaveClue
+)
+
+type slot struct {
+	knowledge yanhuo.CardKnowledge
+
+	// The turn on which the card was drawn, used to find the chop and the
+	// finesse position.
+	drawn int
+
+	// The cards the holder has been led to believe this is by a play clue or
+	// a finesse, or nil if no clue focused on it.
+	candidates []yanhuo.Card
+
+	// Whether the play clue was on a card which is not yet playable, and
+	// waits on another card being played first.
+	delayed bool
+
+	// Whether we inferred a finesse on this card (only for our own hand).
+	finessed bool
+}
+
+func (s *slot) clued() bool {
+	return s.knowledge.Touched || s.candidates != nil
+}
+
+type ConventionStrategy struct {
+	Name    string
+	Verbose bool
+
+	me            yanhuo.PlayerIndex
+	numPlayers    int
+	maxBlueTokens int
+
+	turn     int
+	deckSize int
+
+	table yanhuo.TableTracker
+	slots map[yanhuo.PlayerIndex][]slot
+
+	reason string
+}
+
+func NewConventionStrategy(name string) *ConventionStrategy {
+	return &ConventionStrategy{Name: name}
+}
+
+// Refuses rules with variant suits, since the conventions assume every hint
+// touches exactly the cards it names.
+func (s *ConventionStrategy) CheckRules(rules yanhuo.Rules) error {
+	for _, suit := range rules.Suits {
+		if suit != (yanhuo.SuitRules{}) {
+			return fmt.Errorf("The conventions don't allow for suits which hints touch differently")
+		}
+	}
+	return nil
+}
+
+// Returns the explanation for the most recent action this strategy chose.
+func (s *ConventionStrategy) LastReason() string {
+	return s.reason
+}
+
+func (s *ConventionStrategy) StartGame(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) {
+	s.me = myPlayerIndex
+	s.numPlayers = len(otherPlayersCards) + 1
+	s.maxBlueTokens = blueTokens
+	s.turn = 0
+	s.table.Reset()
+	s.slots = make(map[yanhuo.PlayerIndex][]slot)
+	s.reason = ""
+
+	s.deckSize = 0
+	for range yanhuo.ALL_COLORS {
+		for _, v := range yanhuo.ALL_VALUES {
+			s.deckSize += yanhuo.NumCopies(v)
+		}
+	}
+	s.deckSize -= s.numPlayers * myNumCards
+
+	s.table.UpdateHands(otherPlayersCards)
+	for p := 0; p < s.numPlayers; p++ {
+		hand := make([]slot, myNumCards)
+		for i := range hand {
+			// the first card is the newest
+			hand[i] = slot{knowledge: yanhuo.NewCardKnowledge(), drawn: -i}
+		}
+		s.slots[yanhuo.PlayerIndex(p)] = hand
+	}
+}
+
+func (s *ConventionStrategy) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	s.table.UpdateHands(otherPlayersCards)
+
+	action := s.decide(blueTokens)
+	if s.Verbose {
+		log.Printf("%s: %s (%s)\n", s.Name, action.DebugString(), s.reason)
+	}
+
+	s.takeAction(action)
+	return action
+}
+
+func (s *ConventionStrategy) Rejoin(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int,
+	action yanhuo.Action) {
+	s.table.UpdateHands(otherPlayersCards)
+	s.reason = "replaying the action taken before we joined the game"
+	s.takeAction(action)
+}
+
+func (s *ConventionStrategy) takeAction(action yanhuo.Action) {
+	switch {
+	case action.GiveInformation != nil:
+		s.applyHint(s.me, action.GiveInformation)
+	case action.Discard != nil:
+		s.replace(s.me, action.Discard.Index, s.deckSize > 0)
+	case action.Play != nil:
+		s.replace(s.me, action.Play.Index, s.deckSize > 0)
+	}
+}
+
+func (s *ConventionStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+	switch {
+	case action.GiveInformation != nil:
+		s.applyHint(actor, action.GiveInformation)
+	case action.Discard != nil:
+		s.replace(actor, action.Discard.Index, s.table.Replace(actor, action.Discard.Index))
+	case action.Play != nil:
+		s.replace(actor, action.Play.Index, s.table.Replace(actor, action.Play.Index))
+	}
+}
+
+func (s *ConventionStrategy) ObserveDiscard(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDiscard(p, c, i)
+}
+
+func (s *ConventionStrategy) ObserveDraw(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDraw(p, c, i)
+}
+
+func (s *ConventionStrategy) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	s.table.ObservePlay(p, c, successful)
+	if !successful {
+		return
+	}
+
+	// A card waiting on this one has found its connection. If a card was
+	// clued as this one, it was actually clued as the next one (e.g. we were
+	// given a play clue and somebody else was finessed).
+	next := yanhuo.Card{Color: c.Color, Value: c.Value + 1}
+	for _, hand := range s.slots {
+		for i := range hand {
+			if hand[i].delayed && containsCard(hand[i].candidates, next) {
+				hand[i].candidates = []yanhuo.Card{next}
+				hand[i].delayed = false
+			} else if containsCard(hand[i].candidates, c) {
+				hand[i].candidates = removeCard(hand[i].candidates, c)
+				if len(hand[i].candidates) == 0 && hand[i].knowledge.CanBe(next) {
+					hand[i].candidates = []yanhuo.Card{next}
+				}
+			}
+		}
+	}
+}
+
+//
+// Tracking the table
+//
+
+// Called once player p's play or discard of the card at index i has been
+// resolved.
+func (s *ConventionStrategy) replace(p yanhuo.PlayerIndex, i yanhuo.HandIndex, drew bool) {
+	s.turn++
+	hand := s.slots[p]
+	if int(i) >= len(hand) {
+		return
+	}
+
+	if drew {
+		s.deckSize--
+		hand[i] = slot{knowledge: yanhuo.NewCardKnowledge(), drawn: s.turn}
+	} else {
+		s.slots[p] = append(hand[:i], hand[i+1:]...)
+	}
+}
+
+func (s *ConventionStrategy) applyHint(actor yanhuo.PlayerIndex, hint *yanhuo.GiveInformationAction) {
+	s.turn++
+	receiver := hint.PlayerIndex
+	focus, kind := s.interpret(receiver, s.slots[receiver], hint)
+
+	if receiver != s.me && actor != s.me && kind == kPlayClue {
+		s.checkForFinesse(s.table.Hands[receiver][focus])
+	}
+}
+
+// Someone was given a play clue on c. If it isn't playable yet, and the card
+// it is waiting on isn't anywhere we can see, it must be ours.
+func (s *ConventionStrategy) checkForFinesse(c yanhuo.Card) {
+	if int(c.Value) != s.table.Piles[c.Color]+2 {
+		return
+	}
+
+	connecting := yanhuo.Card{Color: c.Color, Value: c.Value - 1}
+	for _, cards := range s.table.Hands {
+		if containsCard(cards, connecting) {
+			return
+		}
+	}
+
+	i := s.finessePosition(s.me)
+	if i < 0 {
+		return
+	}
+	hand := s.slots[s.me]
+	hand[i].candidates = []yanhuo.Card{connecting}
+	hand[i].finessed = true
+}
+
+// Updates hand (belonging to receiver) with a hint, and works out what the
+// hint means under our conventions. Only public information is used, so
+// everyone at the table reaches the same interpretation, and we can use this
+// to predict how another player will understand a hint we give them.
+func (s *ConventionStrategy) interpret(receiver yanhuo.PlayerIndex, hand []slot, hint *yanhuo.GiveInformationAction) (int, clueKind) {
+	chop := chopIndex(hand)
+
+	newlyTouched := []int{}
+	for _, i := range hint.Cards {
+		if int(i) < len(hand) && !hand[i].clued() {
+			newlyTouched = append(newlyTouched, int(i))
+		}
+	}
+
+	knowledge := make(yanhuo.HandKnowledge, len(hand))
+	for i := range hand {
+		knowledge[i] = hand[i].knowledge
+	}
+	knowledge.ApplyHint(hint)
+	for i := range hand {
+		hand[i].knowledge = knowledge[i]
+		if hand[i].candidates != nil {
+			hand[i].candidates = s.filterCandidates(hand[i].candidates, hand[i].knowledge)
+		}
+	}
+
+	if len(newlyTouched) == 0 {
+		return -1, kNoNewInformation
+	}
+
+	focus := newlyTouched[0]
+	for _, i := range newlyTouched {
+		if i == chop {
+			focus = chop
+			break
+		}
+		if hand[i].drawn > hand[focus].drawn {
+			focus = i
+		}
+	}
+
+	if focus == chop && hint.Value != nil && (hint.Value.Value == 5 || hint.Value.Value == 2) {
+		return focus, kSaveClue
+	}
+
+	possible := s.publiclyPossible(hand[focus].knowledge)
+	playable := []yanhuo.Card{}
+	oneAway := []yanhuo.Card{}
+	for _, c := range possible {
+		if s.table.IsPlayable(c) {
+			playable = append(playable, c)
+		} else if int(c.Value) == s.table.Piles[c.Color]+2 {
+			oneAway = append(oneAway, c)
+		}
+	}
+
+	switch {
+	case len(playable) > 0:
+		hand[focus].candidates = playable
+		return focus, kPlayClue
+	case focus != chop && len(oneAway) > 0:
+		hand[focus].candidates = oneAway
+		hand[focus].delayed = true
+		return focus, kPlayClue
+	default:
+		return focus, kSaveClue
+	}
+}
+
+// Returns the cards consistent with some knowledge, excluding those whose
+// copies have all been played or discarded.
+func (s *ConventionStrategy) publiclyPossible(k yanhuo.CardKnowledge) []yanhuo.Card {
+	possible := []yanhuo.Card{}
+	for _, c := range k.PossibleCards() {
+		if s.unaccountedCopies(c) > 0 {
+			possible = append(possible, c)
+		}
+	}
+	return possible
+}
+
+func (s *ConventionStrategy) filterCandidates(candidates []yanhuo.Card, k yanhuo.CardKnowledge) []yanhuo.Card {
+	filtered := []yanhuo.Card{}
+	for _, c := range candidates {
+		if k.CanBe(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// The number of copies of c which haven't been played or discarded.
+func (s *ConventionStrategy) unaccountedCopies(c yanhuo.Card) int {
+	n := yanhuo.NumCopies(c.Value) - s.table.Discards[c]
+	if int(c.Value) <= s.table.Piles[c.Color] {
+		n--
+	}
+	return n
+}
+
+// Returns the cards our own card at index i could be, given its hint
+// knowledge, any conventional meaning, and every card we can see.
+func (s *ConventionStrategy) ownPossibilities(i int) []yanhuo.Card {
+	sl := s.slots[s.me][i]
+	possible := s.publiclyPossible(sl.knowledge)
+	if sl.candidates != nil && len(sl.candidates) > 0 {
+		possible = sl.candidates
+	}
+
+	visible := map[yanhuo.Card]int{}
+	for _, cards := range s.table.Hands {
+		for _, c := range cards {
+			visible[c]++
+		}
+	}
+
+	filtered := []yanhuo.Card{}
+	for _, c := range possible {
+		if s.unaccountedCopies(c)-visible[c] > 0 {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+//
+// Card properties
+//
+
+// Whether we should save c from being discarded: it's critical, or it's a 2
+// and we can't see the other copy anywhere.
+func (s *ConventionStrategy) worthSaving(holder yanhuo.PlayerIndex, c yanhuo.Card, index int) bool {
+	if s.table.IsCritical(c) {
+		return true
+	}
+	if c.Value != 2 || s.table.IsDead(c) {
+		return false
+	}
+	for p, cards := range s.table.Hands {
+		for i, other := range cards {
+			if other == c && (p != holder || i != index) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Whether c is already going to be played: another copy has been clued, or
+// we believe we hold it.
+func (s *ConventionStrategy) alreadyClued(c yanhuo.Card) bool {
+	for p, cards := range s.table.Hands {
+		for i, other := range cards {
+			if other == c && i < len(s.slots[p]) && s.slots[p][i].clued() {
+				return true
+			}
+		}
+	}
+	for _, sl := range s.slots[s.me] {
+		if len(sl.candidates) == 1 && sl.candidates[0] == c {
+			return true
+		}
+	}
+	return false
+}
+
+func chopIndex(hand []slot) int {
+	chop := -1
+	for i := range hand {
+		if !hand[i].clued() && (chop == -1 || hand[i].drawn < hand[chop].drawn) {
+			chop = i
+		}
+	}
+	return chop
+}
+
+func (s *ConventionStrategy) finessePosition(p yanhuo.PlayerIndex) int {
+	hand := s.slots[p]
+	newest := -1
+	for i := range hand {
+		if !hand[i].clued() && (newest == -1 || hand[i].drawn > hand[newest].drawn) {
+			newest = i
+		}
+	}
+	return newest
+}
+
+//
+// Choosing an action
+//
+
+func (s *ConventionStrategy) decide(blueTokens int) yanhuo.Action {
+	hand := s.slots[s.me]
+
+	for i := range hand {
+		if hand[i].finessed {
+			s.reason = fmt.Sprintf("blind playing card %d, which we were finessed into holding %s",
+				i, describe(hand[i].candidates[0]))
+			return play(i)
+		}
+	}
+
+	if i, ok := s.knownPlayable(); ok {
+		s.reason = fmt.Sprintf("card %d is known to be playable (one of %s)", i, describeAll(s.ownPossibilities(i)))
+		return play(i)
+	}
+
+	if blueTokens > 0 {
+		if hint := s.saveClue(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+		if hint := s.playClue(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+
+	for i := range hand {
+		if possible := s.ownPossibilities(i); len(possible) > 0 && allDead(s, possible) {
+			s.reason = fmt.Sprintf("card %d is known to be useless (one of %s)", i, describeAll(possible))
+			return discard(i)
+		}
+	}
+
+	if blueTokens == s.maxBlueTokens {
+		if hint := s.stallClue(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+
+	if chop := chopIndex(hand); chop >= 0 {
+		s.reason = fmt.Sprintf("nothing better to do, discarding chop (card %d)", chop)
+		return discard(chop)
+	}
+
+	if blueTokens > 0 {
+		if hint := s.stallClue(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+
+	i := s.leastValuableClued()
+	s.reason = fmt.Sprintf("every card is clued, discarding card %d which is least likely to be critical", i)
+	return discard(i)
+}
+
+func (s *ConventionStrategy) knownPlayable() (int, bool) {
+	best := -1
+	var bestValue yanhuo.Value
+	for i := range s.slots[s.me] {
+		possible := s.ownPossibilities(i)
+		if len(possible) == 0 {
+			continue
+		}
+		allPlayable := true
+		for _, c := range possible {
+			if !s.table.IsPlayable(c) {
+				allPlayable = false
+			}
+		}
+		if allPlayable && (best == -1 || possible[0].Value < bestValue) {
+			best, bestValue = i, possible[0].Value
+		}
+	}
+	return best, best != -1
+}
+
+// Looks for a critical card on the next player's chop, which they'll
+// discard unless we save it.
+func (s *ConventionStrategy) saveClue() *yanhuo.GiveInformationAction {
+	next := yanhuo.PlayerIndex((int(s.me) + 1) % s.numPlayers)
+	chop := chopIndex(s.slots[next])
+	if chop < 0 {
+		return nil
+	}
+
+	c := s.table.Hands[next][chop]
+	if !s.worthSaving(next, c, chop) {
+		return nil
+	}
+
+	for _, hint := range s.hintsFor(next, c) {
+		if focus, kind, candidates := s.simulate(next, hint); focus == chop &&
+			(kind == kSaveClue || kind == kPlayClue && s.table.IsPlayable(c) && containsCard(candidates, c)) {
+			s.reason = fmt.Sprintf("saving %s on player %d's chop", describe(c), next)
+			return hint
+		}
+	}
+	return nil
+}
+
+// Looks for a playable card which nobody knows about, in turn order, and a
+// hint which everyone will understand as a play clue on it.
+func (s *ConventionStrategy) playClue() *yanhuo.GiveInformationAction {
+	for offset := 1; offset < s.numPlayers; offset++ {
+		p := yanhuo.PlayerIndex((int(s.me) + offset) % s.numPlayers)
+		for i, c := range s.table.Hands[p] {
+			if !s.table.IsPlayable(c) || s.slots[p][i].clued() || s.alreadyClued(c) {
+				continue
+			}
+
+			for _, hint := range s.hintsFor(p, c) {
+				if s.misleads(p, hint) {
+					continue
+				}
+				if focus, kind, candidates := s.simulate(p, hint); focus == i && kind == kPlayClue &&
+					containsCard(candidates, c) && allPlayable(s, candidates) {
+					s.reason = fmt.Sprintf("play clue on player %d's %s", p, describe(c))
+					return hint
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Looks for a hint which gives nobody a reason to do anything.
+func (s *ConventionStrategy) stallClue() *yanhuo.GiveInformationAction {
+	for offset := 1; offset < s.numPlayers; offset++ {
+		p := yanhuo.PlayerIndex((int(s.me) + offset) % s.numPlayers)
+		for _, c := range s.table.Hands[p] {
+			for _, hint := range s.hintsFor(p, c) {
+				if focus, kind, candidates := s.simulate(p, hint); kind == kNoNewInformation ||
+					kind == kSaveClue && !s.misleads(p, hint) ||
+					kind == kPlayClue && focus >= 0 && s.table.IsPlayable(s.table.Hands[p][focus]) && allPlayable(s, candidates) {
+					s.reason = fmt.Sprintf("stalling with a hint to player %d", p)
+					return hint
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Whether a hint would leave some previously clued card with only wrong
+// candidates, which would confuse its holder.
+func (s *ConventionStrategy) misleads(p yanhuo.PlayerIndex, hint *yanhuo.GiveInformationAction) bool {
+	hand := copySlots(s.slots[p])
+	s.interpret(p, hand, hint)
+	for i := range hand {
+		if hand[i].candidates != nil && !containsCard(hand[i].candidates, s.table.Hands[p][i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ConventionStrategy) simulate(p yanhuo.PlayerIndex, hint *yanhuo.GiveInformationAction) (int, clueKind, []yanhuo.Card) {
+	hand := copySlots(s.slots[p])
+	focus, kind := s.interpret(p, hand, hint)
+	if focus < 0 {
+		return focus, kind, nil
+	}
+	return focus, kind, hand[focus].candidates
+}
+
+func (s *ConventionStrategy) hintsFor(p yanhuo.PlayerIndex, c yanhuo.Card) []*yanhuo.GiveInformationAction {
+	return []*yanhuo.GiveInformationAction{
+		yanhuo.NewValueHint(p, s.table.Hands[p], c.Value),
+		yanhuo.NewColorHint(p, s.table.Hands[p], c.Color),
+	}
+}
+
+func (s *ConventionStrategy) leastValuableClued() int {
+	best, bestCritical := 0, -1
+	for i := range s.slots[s.me] {
+		critical := 0
+		for _, c := range s.ownPossibilities(i) {
+			if s.table.IsCritical(c) {
+				critical++
+			}
+		}
+		if bestCritical == -1 || critical < bestCritical {
+			best, bestCritical = i, critical
+		}
+	}
+	return best
+}
+
+//
+// Helpers
+//
+
+func play(i int) yanhuo.Action {
+	return yanhuo.Action{Play: &yanhuo.PlayAction{Index: yanhuo.HandIndex(i)}}
+}
+
+func discard(i int) yanhuo.Action {
+	return yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: yanhuo.HandIndex(i)}}
+}
+
+func copySlots(hand []slot) []slot {
+	out := make([]slot, len(hand))
+	copy(out, hand)
+	return out
+}
+
+func containsCard(cards []yanhuo.Card, c yanhuo.Card) bool {
+	for _, other := range cards {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+func removeCard(cards []yanhuo.Card, c yanhuo.Card) []yanhuo.Card {
+	out := []yanhuo.Card{}
+	for _, other := range cards {
+		if other != c {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
+func allPlayable(s *ConventionStrategy, cards []yanhuo.Card) bool {
+	for _, c := range cards {
+		if !s.table.IsPlayable(c) {
+			return false
+		}
+	}
+	return len(cards) > 0
+}
+
+func allDead(s *ConventionStrategy, cards []yanhuo.Card) bool {
+	for _, c := range cards {
+		if !s.table.IsDead(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(c yanhuo.Card) string {
+	return c.String()
+}
+
+func describeAll(cards []yanhuo.Card) string {
+	out := ""
+	sep := ""
+	for _, c := range cards {
+		out = fmt.Sprintf("%s%s%s", out, sep, c)
+		sep = ", "
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+package conventions
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"strings"
+	"testing"
+)
+
+func card(value int, color yanhuo.Color) yanhuo.Card {
+	return yanhuo.Card{Value: yanhuo.Value(value), Color: color}
+}
+
+// Starts a 3-player game from player 0's point of view.
+func startGame(others map[yanhuo.PlayerIndex][]yanhuo.Card) *ConventionStrategy {
+	s := NewConventionStrategy("test")
+	s.StartGame(0, others, 4, 8, 3)
+	return s
+}
+
+func TestFiveSaveIsNotPlayed(t *testing.T) {
+	s := startGame(map[yanhuo.PlayerIndex][]yanhuo.Card{
+		1: {card(1, yanhuo.RED), card(2, yanhuo.RED), card(3, yanhuo.RED), card(4, yanhuo.RED)},
+		2: {card(1, yanhuo.BLUE), card(2, yanhuo.BLUE), card(3, yanhuo.BLUE), card(4, yanhuo.BLUE)},
+	})
+
+	// Our chop is the oldest card, at the end of our hand.
+	s.ObserveAction(1, yanhuo.Action{GiveInformation: &yanhuo.GiveInformationAction{
+		PlayerIndex: 0,
+		Cards:       []yanhuo.HandIndex{3},
+		Value:       &yanhuo.ValueInformation{Value: 5},
+	}})
+
+	action := s.Act(0, s.table.Hands, 4, 7, 3)
+	if action.Play != nil {
+		t.Errorf("Should not play a saved 5: %s (%s)", action.DebugString(), s.LastReason())
+	}
+	if action.Discard != nil && action.Discard.Index == 3 {
+		t.Errorf("Should not discard a saved 5: %s (%s)", action.DebugString(), s.LastReason())
+	}
+}
+
+func TestPlayClueIsPlayed(t *testing.T) {
+	s := startGame(map[yanhuo.PlayerIndex][]yanhuo.Card{
+		1: {card(2, yanhuo.RED), card(2, yanhuo.RED), card(3, yanhuo.RED), card(4, yanhuo.RED)},
+		2: {card(2, yanhuo.BLUE), card(2, yanhuo.BLUE), card(3, yanhuo.BLUE), card(4, yanhuo.BLUE)},
+	})
+
+	s.ObserveAction(1, yanhuo.Action{GiveInformation: &yanhuo.GiveInformationAction{
+		PlayerIndex: 0,
+		Cards:       []yanhuo.HandIndex{1},
+		Color:       &yanhuo.ColorInformation{Color: yanhuo.GREEN},
+	}})
+
+	action := s.Act(0, s.table.Hands, 4, 7, 3)
+	if action.Play == nil || action.Play.Index != 1 {
+		t.Errorf("Should play the clued card: %s (%s)", action.DebugString(), s.LastReason())
+	}
+	if !strings.Contains(s.LastReason(), "G1") {
+		t.Errorf("Reason should explain the card is a G1: %s", s.LastReason())
+	}
+}
+
+func TestFinesseIsRecognized(t *testing.T) {
+	s := startGame(map[yanhuo.PlayerIndex][]yanhuo.Card{
+		1: {card(3, yanhuo.RED), card(4, yanhuo.WHITE), card(3, yanhuo.BLUE), card(4, yanhuo.BLUE)},
+		2: {card(2, yanhuo.GREEN), card(3, yanhuo.GREEN), card(3, yanhuo.WHITE), card(4, yanhuo.YELLOW)},
+	})
+
+	// Player 1 play clues player 2's G2 while the G1 is nowhere to be
+	// seen: it must be on our finesse position.
+	s.ObserveAction(1, yanhuo.Action{GiveInformation: yanhuo.NewColorHint(2, s.table.Hands[2], yanhuo.GREEN)})
+
+	action := s.Act(0, s.table.Hands, 4, 7, 3)
+	if action.Play == nil || action.Play.Index != 0 {
+		t.Errorf("Should blind play our finesse position: %s (%s)", action.DebugString(), s.LastReason())
+	}
+}
+
+type scoreObserver struct {
+	yanhuo.BaseObserver
+	games   int
+	strikes int
+}
+
+func (o *scoreObserver) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	if !successful {
+		o.strikes++
+	}
+}
+
+func (o *scoreObserver) GameComplete(won bool, piles map[yanhuo.Color]int) {
+	o.games++
+}
+
+func TestPlayFullGames(t *testing.T) {
+	o := &scoreObserver{}
+	for i := 0; i < 20; i++ {
+		players := []yanhuo.PlayerStrategy{}
+		for p := 0; p < 4; p++ {
+			players = append(players, NewConventionStrategy("test"))
+		}
+
+		game, err := yanhuo.InitializeGame(players, []yanhuo.Observer{o})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+	}
+
+	if o.games != 20 {
+		t.Fatalf("Expected 20 completed games, got %d", o.games)
+	}
+
+	if o.strikes > 20 {
+		t.Errorf("Too many misplays following conventions: %d in %d games", o.strikes, o.games)
+	}
+}
+
+func TestRejoinMidGame(t *testing.T) {
+	players := func() []yanhuo.PlayerStrategy {
+		return []yanhuo.PlayerStrategy{NewConventionStrategy("a"), NewConventionStrategy("b"), NewConventionStrategy("c")}
+	}
+
+	game, err := yanhuo.InitializeGameWithOptions(players(), nil, yanhuo.GameOptions{Seed: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	// fresh players who rejoin halfway should make the same decisions
+	rewound, err := yanhuo.Rewind(record, len(record.Turns)/2, players(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewound.Play()
+	continued := rewound.Record()
+
+	if len(continued.Turns) != len(record.Turns) {
+		t.Fatalf("Continued game took %d turns, original took %d", len(continued.Turns), len(record.Turns))
+	}
+	for i := range record.Turns {
+		if a, b := record.Turns[i].Action.DebugString(), continued.Turns[i].Action.DebugString(); a != b {
+			t.Errorf("Turn %d differs: %s vs %s", i, a, b)
+		}
+	}
+}
+
+func TestRefusesVariantSuits(t *testing.T) {
+	players := func() []yanhuo.PlayerStrategy {
+		return []yanhuo.PlayerStrategy{NewConventionStrategy("a"), NewConventionStrategy("b")}
+	}
+
+	rules := yanhuo.StandardRules()
+	rules.Suits = map[yanhuo.Color]yanhuo.SuitRules{yanhuo.RED: {}}
+	if _, err := yanhuo.InitializeGameWithOptions(players(), nil, yanhuo.GameOptions{Rules: &rules}); err != nil {
+		t.Errorf("Suits touched by the standard rules should be allowed: %v", err)
+	}
+
+	rules.Suits[yanhuo.RED] = yanhuo.SuitRules{ColorHints: yanhuo.TouchAlways}
+	if _, err := yanhuo.InitializeGameWithOptions(players(), nil, yanhuo.GameOptions{Rules: &rules}); err == nil {
+		t.Errorf("Expected conventions to refuse a rainbow suit")
+	}
+}
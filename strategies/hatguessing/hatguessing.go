@@ -0,0 +1,267 @@
+// Package hatguessing implements the "recommendation" hat-guessing strategy
+// described by Cox et al. in "How to Make the Perfect Fireworks Display: Two
+// Strategies for Hanabi".
+//
+// Every hint encodes one recommendation (play or discard a particular card)
+// for each of the other players at once: the hinter adds up the
+// recommendations for everyone it can see, modulo the number of distinct
+// hints it could give, and picks the hint with that number. Each recipient
+// can see every hand but its own, so it can subtract the recommendations it
+// sees from the hint's number to recover its own.
+//
+// It works best with 4 or 5 players, where there are enough distinct hints
+// to encode a useful range of recommendations. A hint's number depends only
+// on who gets it and whether it names a color or a value, so in variants it
+// gives any hint of the right kind which the rules allow. If the rules allow
+// none, because no such hint would touch one of the recipient's cards, it
+// discards instead.
+package hatguessing
+
+import (
+	"github.com/mrjones/yanhuo/core"
+)
+
+const kNoRecommendation = -1
+
+type HatGuessingStrategy struct {
+	rules yanhuo.Rules
+
+	me         yanhuo.PlayerIndex
+	numPlayers int
+	handSize   int
+
+	// What we can see of the table
+	table yanhuo.TableTracker
+
+	myRecommendation int
+	playsSinceHint   int
+}
+
+func NewHatGuessingStrategy() *HatGuessingStrategy {
+	return &HatGuessingStrategy{}
+}
+
+// Learns the rules, so that only hints the rules allow are given.
+func (s *HatGuessingStrategy) ObserveView(v *yanhuo.View) {
+	s.rules = v.Rules()
+}
+
+func (s *HatGuessingStrategy) StartGame(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) {
+	s.me = myPlayerIndex
+	s.numPlayers = len(otherPlayersCards) + 1
+	s.handSize = myNumCards
+	s.table.Reset()
+	s.myRecommendation = kNoRecommendation
+	s.playsSinceHint = 0
+
+	s.table.UpdateHands(otherPlayersCards)
+}
+
+func (s *HatGuessingStrategy) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	s.table.UpdateHands(otherPlayersCards)
+
+	rec := s.myRecommendation
+	if rec != kNoRecommendation && s.isPlay(rec) && s.index(rec) < myNumCards {
+		// Another play since the hint may have made our card unplayable
+		// (e.g. if it was a duplicate), so only risk it while we can afford
+		// to lose a red token.
+		if s.playsSinceHint == 0 || (s.playsSinceHint == 1 && redTokens > 1) {
+			s.myRecommendation = kNoRecommendation
+			return yanhuo.Action{Play: &yanhuo.PlayAction{Index: yanhuo.HandIndex(s.index(rec))}}
+		}
+	}
+
+	if blueTokens > 0 {
+		if hint := s.chooseHint(); hint != nil {
+			s.playsSinceHint = 0
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+
+	index := 0
+	if rec != kNoRecommendation && !s.isPlay(rec) && s.index(rec) < myNumCards {
+		index = s.index(rec)
+	}
+	s.myRecommendation = kNoRecommendation
+	return yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: yanhuo.HandIndex(index)}}
+}
+
+func (s *HatGuessingStrategy) Rejoin(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int,
+	action yanhuo.Action) {
+	s.table.UpdateHands(otherPlayersCards)
+	if action.GiveInformation != nil {
+		s.playsSinceHint = 0
+	} else {
+		s.myRecommendation = kNoRecommendation
+	}
+}
+
+func (s *HatGuessingStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+	switch {
+	case action.GiveInformation != nil:
+		s.observeHint(actor, action.GiveInformation)
+	case action.Discard != nil:
+		s.table.Replace(actor, action.Discard.Index)
+	case action.Play != nil:
+		s.table.Replace(actor, action.Play.Index)
+	}
+}
+
+func (s *HatGuessingStrategy) ObserveDiscard(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDiscard(p, c, i)
+}
+
+func (s *HatGuessingStrategy) ObserveDraw(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDraw(p, c, i)
+}
+
+func (s *HatGuessingStrategy) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	s.playsSinceHint++
+	s.table.ObservePlay(p, c, successful)
+}
+
+func (s *HatGuessingStrategy) observeHint(actor yanhuo.PlayerIndex, hint *yanhuo.GiveInformationAction) {
+	s.playsSinceHint = 0
+
+	sum := s.hintNumber(actor, hint)
+	for p, hand := range s.table.Hands {
+		if p != actor {
+			sum -= s.recommend(hand)
+		}
+	}
+
+	s.myRecommendation = mod(sum, s.numHints())
+}
+
+//
+// Encoding recommendations as hints
+//
+
+// The number of distinct hints a player can give: one of each type to each
+// other player.
+func (s *HatGuessingStrategy) numHints() int {
+	return 2 * (s.numPlayers - 1)
+}
+
+// Recommendations are numbered with all the plays first, followed by as many
+// discards as there are hints left to encode them.
+func (s *HatGuessingStrategy) numPlayRecommendations() int {
+	if s.handSize < s.numHints() {
+		return s.handSize
+	}
+	return s.numHints() - 1
+}
+
+func (s *HatGuessingStrategy) isPlay(rec int) bool {
+	return rec < s.numPlayRecommendations()
+}
+
+func (s *HatGuessingStrategy) index(rec int) int {
+	if s.isPlay(rec) {
+		return rec
+	}
+	return rec - s.numPlayRecommendations()
+}
+
+func (s *HatGuessingStrategy) playRecommendation(i int) int {
+	if i >= s.numPlayRecommendations() {
+		return kNoRecommendation
+	}
+	return i
+}
+
+func (s *HatGuessingStrategy) discardRecommendation(i int) int {
+	rec := s.numPlayRecommendations() + i
+	if rec >= s.numHints() {
+		return kNoRecommendation
+	}
+	return rec
+}
+
+func (s *HatGuessingStrategy) hintNumber(actor yanhuo.PlayerIndex, hint *yanhuo.GiveInformationAction) int {
+	offset := mod(int(hint.PlayerIndex)-int(actor), s.numPlayers)
+	n := 2 * (offset - 1)
+	if hint.Value != nil {
+		n++
+	}
+	return n
+}
+
+func (s *HatGuessingStrategy) chooseHint() *yanhuo.GiveInformationAction {
+	sum := 0
+	for _, hand := range s.table.Hands {
+		sum += s.recommend(hand)
+	}
+	n := mod(sum, s.numHints())
+
+	target := yanhuo.PlayerIndex((int(s.me) + n/2 + 1) % s.numPlayers)
+	for _, hint := range s.rules.LegalHints(target, s.table.Hands[target]) {
+		if (hint.Color != nil) == (n%2 == 0) {
+			return hint
+		}
+	}
+	return nil
+}
+
+//
+// Choosing recommendations
+//
+
+// Recommends, in order of preference: playing a playable 5, playing the
+// lowest playable card, discarding a card which can never be played,
+// discarding the highest card which isn't the last copy, or discarding the
+// first card.
+func (s *HatGuessingStrategy) recommend(hand []yanhuo.Card) int {
+	best := -1
+	for i, c := range hand {
+		if !s.table.IsPlayable(c) || s.playRecommendation(i) == kNoRecommendation {
+			continue
+		}
+		if best == -1 || c.Value == 5 && hand[best].Value != 5 ||
+			c.Value < hand[best].Value && hand[best].Value != 5 {
+			best = i
+		}
+	}
+	if best != -1 {
+		return s.playRecommendation(best)
+	}
+
+	for i, c := range hand {
+		if s.table.IsDead(c) && s.discardRecommendation(i) != kNoRecommendation {
+			return s.discardRecommendation(i)
+		}
+	}
+
+	for i, c := range hand {
+		if s.discardRecommendation(i) == kNoRecommendation {
+			break
+		}
+		if !s.table.IsCritical(c) && (best == -1 || c.Value > hand[best].Value) {
+			best = i
+		}
+	}
+	if best != -1 {
+		return s.discardRecommendation(best)
+	}
+
+	return s.discardRecommendation(0)
+}
+
+func mod(a, n int) int {
+	return ((a % n) + n) % n
+}
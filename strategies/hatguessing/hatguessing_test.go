@@ -0,0 +1,154 @@
+package hatguessing
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"testing"
+)
+
+type scoreObserver struct {
+	yanhuo.BaseObserver
+	games int
+	total int
+}
+
+func (o *scoreObserver) GameComplete(won bool, piles map[yanhuo.Color]int) {
+	o.games++
+	for _, height := range piles {
+		o.total += height
+	}
+}
+
+func TestRecommendationRoundTrip(t *testing.T) {
+	s := NewHatGuessingStrategy()
+	s.StartGame(0, map[yanhuo.PlayerIndex][]yanhuo.Card{1: nil, 2: nil, 3: nil, 4: nil}, 4, 8, 3)
+
+	for rec := 0; rec < s.numHints(); rec++ {
+		var again int
+		if s.isPlay(rec) {
+			again = s.playRecommendation(s.index(rec))
+		} else {
+			again = s.discardRecommendation(s.index(rec))
+		}
+		if again != rec {
+			t.Errorf("Recommendation %d decoded as index %d, which re-encodes as %d", rec, s.index(rec), again)
+		}
+	}
+}
+
+func TestHintDecoding(t *testing.T) {
+	hands := map[yanhuo.PlayerIndex][]yanhuo.Card{
+		0: {{Value: 1, Color: yanhuo.RED}, {Value: 3, Color: yanhuo.RED}, {Value: 4, Color: yanhuo.BLUE}, {Value: 5, Color: yanhuo.GREEN}},
+		1: {{Value: 2, Color: yanhuo.WHITE}, {Value: 1, Color: yanhuo.BLUE}, {Value: 4, Color: yanhuo.BLUE}, {Value: 2, Color: yanhuo.GREEN}},
+		2: {{Value: 3, Color: yanhuo.YELLOW}, {Value: 3, Color: yanhuo.WHITE}, {Value: 4, Color: yanhuo.RED}, {Value: 2, Color: yanhuo.GREEN}},
+		3: {{Value: 1, Color: yanhuo.YELLOW}, {Value: 1, Color: yanhuo.YELLOW}, {Value: 1, Color: yanhuo.GREEN}, {Value: 5, Color: yanhuo.WHITE}},
+		4: {{Value: 4, Color: yanhuo.WHITE}, {Value: 5, Color: yanhuo.RED}, {Value: 2, Color: yanhuo.YELLOW}, {Value: 3, Color: yanhuo.GREEN}},
+	}
+
+	players := make([]*HatGuessingStrategy, len(hands))
+	for p := range players {
+		others := map[yanhuo.PlayerIndex][]yanhuo.Card{}
+		for q, cards := range hands {
+			if int(q) != p {
+				others[q] = cards
+			}
+		}
+		players[p] = NewHatGuessingStrategy()
+		players[p].StartGame(yanhuo.PlayerIndex(p), others, 4, 8, 3)
+	}
+
+	hint := players[0].chooseHint()
+	for p := 1; p < len(players); p++ {
+		players[p].ObserveAction(0, yanhuo.Action{GiveInformation: hint})
+		expected := players[0].recommend(hands[yanhuo.PlayerIndex(p)])
+		if players[p].myRecommendation != expected {
+			t.Errorf("Player %d decoded recommendation %d, expected %d",
+				p, players[p].myRecommendation, expected)
+		}
+	}
+}
+
+func TestPlayFullGames(t *testing.T) {
+	o := &scoreObserver{}
+	for i := 0; i < 20; i++ {
+		players := []yanhuo.PlayerStrategy{}
+		for p := 0; p < 5; p++ {
+			players = append(players, NewHatGuessingStrategy())
+		}
+
+		game, err := yanhuo.InitializeGame(players, []yanhuo.Observer{o})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+	}
+
+	if o.games != 20 {
+		t.Fatalf("Expected 20 completed games, got %d", o.games)
+	}
+
+	if average := float64(o.total) / float64(o.games); average < 15 {
+		t.Errorf("Average score (%f) is much lower than expected", average)
+	}
+}
+
+func TestPlayVariantGames(t *testing.T) {
+	rules := yanhuo.StandardRules()
+	rules.Suits = map[yanhuo.Color]yanhuo.SuitRules{
+		yanhuo.RED:  {ValueHints: yanhuo.TouchAlways}, // pink
+		yanhuo.BLUE: {ColorHints: yanhuo.TouchAlways}, // rainbow
+	}
+
+	// the game panics if a hint touches the wrong cards
+	o := &scoreObserver{}
+	for seed := int64(1); seed <= 20; seed++ {
+		players := []yanhuo.PlayerStrategy{}
+		for p := 0; p < 4; p++ {
+			players = append(players, NewHatGuessingStrategy())
+		}
+
+		game, err := yanhuo.InitializeGameWithOptions(players, []yanhuo.Observer{o}, yanhuo.GameOptions{Seed: seed, Rules: &rules})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+	}
+
+	if average := float64(o.total) / float64(o.games); average < 15 {
+		t.Errorf("Average score (%f) is much lower than expected", average)
+	}
+}
+
+func TestRejoinMidGame(t *testing.T) {
+	players := func() []yanhuo.PlayerStrategy {
+		out := []yanhuo.PlayerStrategy{}
+		for i := 0; i < 4; i++ {
+			out = append(out, NewHatGuessingStrategy())
+		}
+		return out
+	}
+
+	game, err := yanhuo.InitializeGameWithOptions(players(), nil, yanhuo.GameOptions{Seed: 11})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	record := game.Record()
+
+	// fresh players who rejoin halfway should make the same decisions
+	rewound, err := yanhuo.Rewind(record, len(record.Turns)/2, players(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewound.Play()
+	continued := rewound.Record()
+
+	if len(continued.Turns) != len(record.Turns) {
+		t.Fatalf("Continued game took %d turns, original took %d", len(continued.Turns), len(record.Turns))
+	}
+	for i := range record.Turns {
+		if a, b := record.Turns[i].Action.DebugString(), continued.Turns[i].Action.DebugString(); a != b {
+			t.Errorf("Turn %d differs: %s vs %s", i, a, b)
+		}
+	}
+}
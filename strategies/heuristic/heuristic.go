@@ -0,0 +1,279 @@
+// Package heuristic implements simple baseline strategies which only act on
+// what hints have told them:
+//
+//   - play a card if it's known to be playable,
+//   - otherwise, hint another player about a playable card,
+//   - otherwise, discard the oldest card.
+//
+// The "safe discarder" variant never discards a card that it knows is the
+// last copy of a card that is still needed, unless it has no other choice.
+package heuristic
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"math/rand"
+)
+
+type HeuristicStrategy struct {
+	safeDiscards bool
+	rand         *rand.Rand
+	rules        yanhuo.Rules
+
+	me         yanhuo.PlayerIndex
+	numPlayers int
+	turn       int
+	deckSize   int
+
+	table     yanhuo.TableTracker
+	knowledge map[yanhuo.PlayerIndex]yanhuo.HandKnowledge
+	drawn     []int // when each of our cards was drawn
+}
+
+// The seed is used to choose between equally good hints.
+func NewHeuristicStrategy(seed int64) *HeuristicStrategy {
+	return &HeuristicStrategy{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Like NewHeuristicStrategy, but never discards a card known to be critical
+// if there is anything else to do.
+func NewSafeDiscardStrategy(seed int64) *HeuristicStrategy {
+	s := NewHeuristicStrategy(seed)
+	s.safeDiscards = true
+	return s
+}
+
+// Learns the rules, so hints are chosen and interpreted by them.
+func (s *HeuristicStrategy) ObserveView(v *yanhuo.View) {
+	s.rules = v.Rules()
+}
+
+func (s *HeuristicStrategy) StartGame(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) {
+	s.me = myPlayerIndex
+	s.numPlayers = len(otherPlayersCards) + 1
+	s.turn = 0
+	s.table.Reset()
+	s.knowledge = make(map[yanhuo.PlayerIndex]yanhuo.HandKnowledge)
+
+	s.deckSize = len(yanhuo.NewDeck()) - s.numPlayers*myNumCards
+
+	s.table.UpdateHands(otherPlayersCards)
+	for p := 0; p < s.numPlayers; p++ {
+		s.knowledge[yanhuo.PlayerIndex(p)] = yanhuo.NewHandKnowledge(myNumCards)
+	}
+
+	// the last card dealt is the newest
+	s.drawn = make([]int, myNumCards)
+	for i := range s.drawn {
+		s.drawn[i] = i - myNumCards
+	}
+}
+
+func (s *HeuristicStrategy) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	s.table.UpdateHands(otherPlayersCards)
+
+	action := s.decide(blueTokens)
+	s.takeAction(action)
+	return action
+}
+
+func (s *HeuristicStrategy) Rejoin(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int,
+	action yanhuo.Action) {
+	s.table.UpdateHands(otherPlayersCards)
+	s.takeAction(action)
+}
+
+func (s *HeuristicStrategy) takeAction(action yanhuo.Action) {
+	switch {
+	case action.GiveInformation != nil:
+		s.applyHint(action.GiveInformation)
+	case action.Discard != nil:
+		s.replace(s.me, action.Discard.Index, s.deckSize > 0)
+	case action.Play != nil:
+		s.replace(s.me, action.Play.Index, s.deckSize > 0)
+	}
+}
+
+func (s *HeuristicStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+	switch {
+	case action.GiveInformation != nil:
+		s.applyHint(action.GiveInformation)
+	case action.Discard != nil:
+		s.replace(actor, action.Discard.Index, s.table.Replace(actor, action.Discard.Index))
+	case action.Play != nil:
+		s.replace(actor, action.Play.Index, s.table.Replace(actor, action.Play.Index))
+	}
+}
+
+func (s *HeuristicStrategy) ObserveDiscard(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDiscard(p, c, i)
+}
+
+func (s *HeuristicStrategy) ObserveDraw(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDraw(p, c, i)
+}
+
+func (s *HeuristicStrategy) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	s.table.ObservePlay(p, c, successful)
+}
+
+func (s *HeuristicStrategy) decide(blueTokens int) yanhuo.Action {
+	for i := range s.knowledge[s.me] {
+		if possible := s.ownPossibilities(i); len(possible) > 0 && s.all(possible, s.table.IsPlayable) {
+			return yanhuo.Action{Play: &yanhuo.PlayAction{Index: yanhuo.HandIndex(i)}}
+		}
+	}
+
+	if blueTokens > 0 {
+		if hint := s.hintPlayableCard(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+
+	oldest := -1
+	for i := range s.drawn {
+		if s.safeDiscards && s.all(s.ownPossibilities(i), s.table.IsCritical) {
+			continue
+		}
+		if oldest == -1 || s.drawn[i] < s.drawn[oldest] {
+			oldest = i
+		}
+	}
+
+	if oldest == -1 {
+		// Everything we hold is critical. Stall if we can, rather than
+		// throw one away.
+		if blueTokens > 0 {
+			if hint := s.anyHint(); hint != nil {
+				return yanhuo.Action{GiveInformation: hint}
+			}
+		}
+		oldest = 0
+	}
+
+	return yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: yanhuo.HandIndex(oldest)}}
+}
+
+// Picks a playable card in another player's hand, which they don't already
+// know is playable, and tells them whichever of its color or value they
+// don't know yet.
+func (s *HeuristicStrategy) hintPlayableCard() *yanhuo.GiveInformationAction {
+	hints := []*yanhuo.GiveInformationAction{}
+
+	for offset := 1; offset < s.numPlayers; offset++ {
+		p := yanhuo.PlayerIndex((int(s.me) + offset) % s.numPlayers)
+		hand := s.table.Hands[p]
+		for i, c := range hand {
+			k := s.knowledge[p][i]
+			if !s.table.IsPlayable(c) || s.all(k.PossibleCards(), s.table.IsPlayable) {
+				continue
+			}
+
+			if _, known := k.Color(); !known && s.rules.CanName(c.Color) {
+				hints = append(hints, s.rules.ColorHint(p, hand, c.Color))
+			} else {
+				hints = append(hints, s.rules.ValueHint(p, hand, c.Value))
+			}
+		}
+	}
+
+	if len(hints) == 0 {
+		return nil
+	}
+	return hints[s.rand.Intn(len(hints))]
+}
+
+func (s *HeuristicStrategy) anyHint() *yanhuo.GiveInformationAction {
+	p := yanhuo.PlayerIndex((int(s.me) + 1) % s.numPlayers)
+	hints := s.rules.LegalHints(p, s.table.Hands[p])
+	if len(hints) == 0 {
+		return nil
+	}
+	return hints[s.rand.Intn(len(hints))]
+}
+
+//
+// Tracking the table
+//
+
+func (s *HeuristicStrategy) applyHint(hint *yanhuo.GiveInformationAction) {
+	s.turn++
+	s.knowledge[hint.PlayerIndex].ApplyHintWithRules(hint, s.rules)
+}
+
+// Called once player p's play or discard of the card at index i has been
+// resolved.
+func (s *HeuristicStrategy) replace(p yanhuo.PlayerIndex, i yanhuo.HandIndex, drew bool) {
+	s.turn++
+	if int(i) >= len(s.knowledge[p]) {
+		return
+	}
+
+	s.knowledge[p] = s.knowledge[p].Replace(i, drew)
+	if drew {
+		s.deckSize--
+	}
+
+	if p == s.me {
+		if drew {
+			s.drawn[i] = s.turn
+		} else {
+			s.drawn = append(s.drawn[:i], s.drawn[i+1:]...)
+		}
+	}
+}
+
+// Returns the cards our own card at index i could be, given what we've been
+// told and every card we can see.
+func (s *HeuristicStrategy) ownPossibilities(i int) []yanhuo.Card {
+	seen := map[yanhuo.Card]int{}
+	for _, cards := range s.table.Hands {
+		for _, c := range cards {
+			seen[c]++
+		}
+	}
+	for c, n := range s.table.Discards {
+		seen[c] += n
+	}
+	for color, height := range s.table.Piles {
+		for v := 1; v <= height; v++ {
+			seen[yanhuo.Card{Color: color, Value: yanhuo.Value(v)}]++
+		}
+	}
+
+	possible := []yanhuo.Card{}
+	for _, c := range s.knowledge[s.me][i].PossibleCards() {
+		if seen[c] < yanhuo.NumCopies(c.Value) {
+			possible = append(possible, c)
+		}
+	}
+	return possible
+}
+
+//
+// Card properties
+//
+
+func (s *HeuristicStrategy) all(cards []yanhuo.Card, f func(yanhuo.Card) bool) bool {
+	for _, c := range cards {
+		if !f(c) {
+			return false
+		}
+	}
+	return len(cards) > 0
+}
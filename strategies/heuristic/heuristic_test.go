@@ -0,0 +1,83 @@
+package heuristic
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"testing"
+)
+
+func card(value int, color yanhuo.Color) yanhuo.Card {
+	return yanhuo.Card{Value: yanhuo.Value(value), Color: color}
+}
+
+// Starts a 2-player game from player 0's point of view.
+func startGame(s *HeuristicStrategy) map[yanhuo.PlayerIndex][]yanhuo.Card {
+	others := map[yanhuo.PlayerIndex][]yanhuo.Card{
+		1: {card(3, yanhuo.RED), card(4, yanhuo.RED), card(3, yanhuo.BLUE), card(4, yanhuo.BLUE), card(2, yanhuo.GREEN)},
+	}
+	s.StartGame(0, others, 5, 8, 3)
+	return others
+}
+
+func valueHint(p yanhuo.PlayerIndex, v int, cards ...yanhuo.HandIndex) yanhuo.Action {
+	return yanhuo.Action{GiveInformation: &yanhuo.GiveInformationAction{
+		PlayerIndex: p,
+		Cards:       cards,
+		Value:       &yanhuo.ValueInformation{Value: yanhuo.Value(v)},
+	}}
+}
+
+func TestPlaysKnownPlayableCard(t *testing.T) {
+	s := NewHeuristicStrategy(1)
+	others := startGame(s)
+
+	if action := s.Act(0, others, 5, 8, 3); action.Play != nil {
+		t.Errorf("Nothing is known to be playable yet, but played: %s", action.DebugString())
+	}
+
+	// every 1 is playable at the start
+	s.ObserveAction(1, valueHint(0, 1, 2))
+	action := s.Act(0, others, 5, 7, 3)
+	if action.Play == nil || action.Play.Index != 2 {
+		t.Errorf("Expected to play the 1 at index 2, got %s", action.DebugString())
+	}
+}
+
+func TestSafeDiscardKeepsCriticalCard(t *testing.T) {
+	// The card at index 0 is the oldest, so the one discarded by default.
+	s := NewHeuristicStrategy(1)
+	others := startGame(s)
+	s.ObserveAction(1, valueHint(0, 5, 0))
+	if action := s.Act(0, others, 5, 0, 3); action.Discard == nil || action.Discard.Index != 0 {
+		t.Errorf("Expected heuristic to discard its oldest card, got %s", action.DebugString())
+	}
+
+	// every 5 is critical until it's played
+	s = NewSafeDiscardStrategy(1)
+	others = startGame(s)
+	s.ObserveAction(1, valueHint(0, 5, 0))
+	action := s.Act(0, others, 5, 0, 3)
+	if action.Discard == nil || action.Discard.Index == 0 {
+		t.Errorf("Expected safediscard to keep its 5, got %s", action.DebugString())
+	}
+}
+
+func TestPlayFullGames(t *testing.T) {
+	total := 0
+	for seed := int64(1); seed <= 10; seed++ {
+		players := []yanhuo.PlayerStrategy{}
+		for i := 0; i < 3; i++ {
+			players = append(players, NewSafeDiscardStrategy(seed+int64(i)))
+		}
+		game, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+		total += game.Record().Score
+	}
+
+	if total < 10*8 {
+		t.Errorf("Expected safediscard to average at least 8, got %.1f", float64(total)/10)
+	}
+}
@@ -9,11 +9,28 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 type HttpClientStrategy struct {
 	remoteEndpoint *url.URL
 	httpClient *http.Client
+
+	retries         int
+	requestObserver RequestObserver
+
+	// set if the game gives us a View, to send the board along with each
+	// message
+	view *yanhuo.View
+	numPlayers int
+}
+
+// RequestObserver is told about every request made to the remote strategy,
+// e.g. to export metrics.
+type RequestObserver interface {
+	// attempt counts from 0, and is non-zero for retries. err is set if the
+	// request failed, including when the server returned a 5xx status.
+	ObserveRequest(messageType string, latency time.Duration, attempt int, err error)
 }
 
 func NewHttpClientStrategy(remoteEndpoint *url.URL) *HttpClientStrategy {
@@ -23,6 +40,40 @@ func NewHttpClientStrategy(remoteEndpoint *url.URL) *HttpClientStrategy {
 	}
 }
 
+// Retries requests which fail, or get a 5xx response, up to n times. By
+// default requests are not retried.
+func (p *HttpClientStrategy) SetRetries(n int) {
+	p.retries = n
+}
+
+func (p *HttpClientStrategy) SetRequestObserver(o RequestObserver) {
+	p.requestObserver = o
+}
+
+// Sends payload to the remote strategy, retrying as configured. Once retries
+// are exhausted, returns the last response or error.
+func (p *HttpClientStrategy) post(messageType string, payload []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := p.httpClient.Post(p.remoteEndpoint.String(), "application/json", bytes.NewReader(payload))
+
+		failure := err
+		if err == nil && resp.StatusCode >= 500 {
+			failure = fmt.Errorf("Server error: %s", resp.Status)
+		}
+		if p.requestObserver != nil {
+			p.requestObserver.ObserveRequest(messageType, time.Since(start), attempt, failure)
+		}
+
+		if failure == nil || attempt >= p.retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+}
+
 type GameState struct {
 	MyPlayerIndex int
 	OtherPlayersCards map[string][]yanhuo.Card
@@ -40,6 +91,72 @@ type Transmission struct {
 	MessageType string
 	GameState *GameState `json:",omitempty"`
 	Observation *Observation `json:",omitempty"`
+
+	// Set on "Error" messages, which tell a player why its action was
+	// rejected.
+	Error string `json:",omitempty"`
+
+	// The public state of the table, when the sender has a View of the game
+	// (see yanhuo.ViewObserver). Strategies can keep track of all this
+	// themselves, but clients for people need it to draw the table.
+	Board *Board `json:",omitempty"`
+}
+
+// Board is what everyone at the table can see, apart from the cards in the
+// players' hands.
+type Board struct {
+	Rules yanhuo.Rules
+	Piles map[yanhuo.Color]int
+	Discards []yanhuo.Card
+	DeckSize int
+	Score int
+
+	// What each player has been told about their cards by hints, keyed by
+	// player index as in GameState.OtherPlayersCards.
+	Knowledge map[string][]CardKnowledge
+}
+
+// CardKnowledge lists what a card could be, given the hints about it.
+type CardKnowledge struct {
+	Colors []yanhuo.Color
+	Values []yanhuo.Value
+}
+
+// Returns the Board of a game with numPlayers players, as seen through v.
+func NewBoard(v *yanhuo.View, numPlayers int) *Board {
+	board := &Board{
+		Rules: v.Rules(),
+		Piles: v.Piles(),
+		Discards: v.Discards(),
+		DeckSize: v.DeckSize(),
+		Score: v.Score(),
+		Knowledge: map[string][]CardKnowledge{},
+	}
+	for i := 0; i < numPlayers; i++ {
+		cards := []CardKnowledge{}
+		for _, k := range v.Knowledge(yanhuo.PlayerIndex(i)) {
+			cards = append(cards, CardKnowledge{Colors: k.PossibleColors(), Values: k.PossibleValues()})
+		}
+		board.Knowledge[fmt.Sprintf("%d", i)] = cards
+	}
+	return board
+}
+
+// Returns the GameState sent to a remote strategy, from the arguments to
+// StartGame or Act.
+func NewGameState(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) *GameState {
+	return &GameState{
+		MyPlayerIndex: int(myPlayerIndex),
+		OtherPlayersCards: translateCardMap(otherPlayersCards),
+		MyCardCount: myNumCards,
+		BlueTokens: blueTokens,
+		RedTokens: redTokens,
+	}
 }
 
 func translateCardMap(in map[yanhuo.PlayerIndex][]yanhuo.Card) map[string][]yanhuo.Card {
@@ -52,21 +169,28 @@ func translateCardMap(in map[yanhuo.PlayerIndex][]yanhuo.Card) map[string][]yanh
 	return out
 }
 
+func (p *HttpClientStrategy) ObserveView(v *yanhuo.View) {
+	p.view = v
+}
+
+func (p *HttpClientStrategy) board() *Board {
+	if p.view == nil {
+		return nil
+	}
+	return NewBoard(p.view, p.numPlayers)
+}
+
 func (p *HttpClientStrategy) StartGame(
 	myPlayerIndex yanhuo.PlayerIndex,
 	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
 	myNumCards int,
 	blueTokens int,
 	redTokens int) {
+	p.numPlayers = len(otherPlayersCards) + 1
 	transmission := Transmission{
 		MessageType: "StartGame",
-		GameState: &GameState{
-			MyPlayerIndex: int(myPlayerIndex),
-			OtherPlayersCards: translateCardMap(otherPlayersCards),
-			MyCardCount: myNumCards,
-			BlueTokens: blueTokens,
-			RedTokens: redTokens,
-		},
+		GameState: NewGameState(myPlayerIndex, otherPlayersCards, myNumCards, blueTokens, redTokens),
+		Board: p.board(),
 	}
 	
 	payload, err := json.Marshal(transmission)
@@ -75,7 +199,7 @@ func (p *HttpClientStrategy) StartGame(
 	}
 
 	fmt.Printf("Transmitting %s\n", string(payload))
-	_, err = p.httpClient.Post(p.remoteEndpoint.String(), "application/json", bytes.NewReader(payload))
+	_, err = p.post(transmission.MessageType, payload)
 	
 	if err != nil {
 		panic(err)
@@ -90,13 +214,8 @@ func (p *HttpClientStrategy) Act(
 	redTokens int) yanhuo.Action {
 	transmission := Transmission{
 		MessageType: "ActionRequest",
-		GameState: &GameState{
-			MyPlayerIndex: int(myPlayerIndex),
-			OtherPlayersCards: translateCardMap(otherPlayersCards),
-			MyCardCount: myNumCards,
-			BlueTokens: blueTokens,
-			RedTokens: redTokens,
-		},
+		GameState: NewGameState(myPlayerIndex, otherPlayersCards, myNumCards, blueTokens, redTokens),
+		Board: p.board(),
 	}
 	
 	payload, err := json.Marshal(transmission)
@@ -105,7 +224,7 @@ func (p *HttpClientStrategy) Act(
 	}
 
 	fmt.Printf("Transmitting %s\n", string(payload))
-	resp, err := p.httpClient.Post(p.remoteEndpoint.String(), "application/json", bytes.NewReader(payload))
+	resp, err := p.post(transmission.MessageType, payload)
 	if err != nil {
 		panic(err)
 	}
@@ -131,6 +250,7 @@ func (p *HttpClientStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanh
 	transmission := Transmission{
 		MessageType: "Observation",
 		Observation: &Observation{Actor: actor, Action: action},
+		Board: p.board(),
 	}
 
 	payload, err := json.Marshal(transmission)
@@ -139,6 +259,6 @@ func (p *HttpClientStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanh
 	}
 
 	fmt.Printf("Transmitting %s\n", string(payload))
-	p.httpClient.Post(p.remoteEndpoint.String(), "application/json", bytes.NewReader(payload))
+	p.post(transmission.MessageType, payload)
 }
 
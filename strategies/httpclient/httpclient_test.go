@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 type TestRoundTripper struct {
@@ -135,3 +136,102 @@ func TestAct(t *testing.T) {
 		t.Errorf("Should have discarded card 2: %s", decision.DebugString())
 	}
 }
+
+// Fails the first `failures` requests with a 503, then returns Response.
+type FlakyRoundTripper struct {
+	TestRoundTripper
+	failures int
+}
+
+func (tr *FlakyRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	if tr.failures > 0 {
+		tr.failures--
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Status:     "503 Service Unavailable",
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}, nil
+	}
+	return tr.TestRoundTripper.RoundTrip(request)
+}
+
+type requestLog struct {
+	attempts []int
+	errors   int
+}
+
+func (l *requestLog) ObserveRequest(messageType string, latency time.Duration, attempt int, err error) {
+	l.attempts = append(l.attempts, attempt)
+	if err != nil {
+		l.errors++
+	}
+}
+
+func TestActRetries(t *testing.T) {
+	s, _ := makeStrategy(t)
+	rt := &FlakyRoundTripper{failures: 2}
+	rt.Response = makeOkResponse("{\"Play\":{\"Index\":1}}")
+	s.httpClient = &http.Client{Transport: rt}
+
+	requests := &requestLog{}
+	s.SetRetries(2)
+	s.SetRequestObserver(requests)
+
+	decision := s.Act(yanhuo.PlayerIndex(0), map[yanhuo.PlayerIndex][]yanhuo.Card{}, 4, 8, 3)
+
+	if decision.Play == nil || decision.Play.Index != 1 {
+		t.Errorf("Should have played card 1 after retrying: %s", decision.DebugString())
+	}
+	if fmt.Sprint(requests.attempts) != "[0 1 2]" || requests.errors != 2 {
+		t.Errorf("Expected two failed attempts and a success, got attempts %v with %d errors",
+			requests.attempts, requests.errors)
+	}
+}
+
+// Records every transmission, and answers each one by discarding the first
+// card.
+type DiscardingRoundTripper struct {
+	Transmissions []*Transmission
+}
+
+func (tr *DiscardingRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	var transmission *Transmission
+	if err := json.NewDecoder(request.Body).Decode(&transmission); err != nil {
+		return nil, err
+	}
+	tr.Transmissions = append(tr.Transmissions, transmission)
+	return makeOkResponse("{\"Discard\":{\"Index\":0}}"), nil
+}
+
+func TestBoard(t *testing.T) {
+	rt := &DiscardingRoundTripper{}
+	players := []yanhuo.PlayerStrategy{}
+	for i := 0; i < 2; i++ {
+		s, _ := makeStrategy(t)
+		s.httpClient = &http.Client{Transport: rt}
+		players = append(players, s)
+	}
+
+	game, err := yanhuo.InitializeGame(players, []yanhuo.Observer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+
+	first := rt.Transmissions[0]
+	if first.MessageType != "StartGame" || first.Board == nil {
+		t.Fatalf("Expected the game to start with the board: %+v", first)
+	}
+	if first.Board.DeckSize != 40 || len(first.Board.Discards) != 0 ||
+		len(first.Board.Knowledge) != 2 || len(first.Board.Knowledge["1"]) != 5 {
+		t.Errorf("Unexpected board at the start: %+v", first.Board)
+	}
+	if k := first.Board.Knowledge["0"][0]; len(k.Colors) != 5 || len(k.Values) != 5 {
+		t.Errorf("Nothing should be known about card 0 at the start: %+v", k)
+	}
+
+	last := rt.Transmissions[len(rt.Transmissions)-1]
+	if last.Board == nil || last.Board.DeckSize != 0 || len(last.Board.Discards) < 40 {
+		t.Errorf("Unexpected board at the end: %+v", last.Board)
+	}
+}
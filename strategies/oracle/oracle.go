@@ -0,0 +1,198 @@
+// Package oracle implements a strategy which cheats: it looks at its own
+// cards. It can only be used in games started with
+// yanhuo.GameOptions.AllowCheating, and exists to give an upper bound on how
+// well an honest strategy could have done with the same deal.
+package oracle
+
+import (
+	"github.com/mrjones/yanhuo/core"
+)
+
+type OracleStrategy struct {
+	rules yanhuo.Rules
+
+	me            yanhuo.PlayerIndex
+	numPlayers    int
+	maxBlueTokens int
+
+	myCards []yanhuo.Card
+	table   yanhuo.TableTracker
+}
+
+func NewOracleStrategy() *OracleStrategy {
+	return &OracleStrategy{}
+}
+
+// Learns the rules, so that only hints the rules allow are given.
+func (s *OracleStrategy) ObserveView(v *yanhuo.View) {
+	s.rules = v.Rules()
+}
+
+func (s *OracleStrategy) StartGame(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) {
+	s.me = myPlayerIndex
+	s.numPlayers = len(otherPlayersCards) + 1
+	s.maxBlueTokens = blueTokens
+	s.table.Reset()
+	s.table.UpdateHands(otherPlayersCards)
+}
+
+func (s *OracleStrategy) ObserveOwnCards(cards []yanhuo.Card) {
+	s.myCards = cards
+}
+
+func (s *OracleStrategy) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	s.table.UpdateHands(otherPlayersCards)
+
+	if i := s.bestPlay(); i >= 0 {
+		return yanhuo.Action{Play: &yanhuo.PlayAction{Index: yanhuo.HandIndex(i)}}
+	}
+
+	// Discarding a useless card gets a blue token back for free, unless we
+	// already have them all.
+	if blueTokens < s.maxBlueTokens {
+		for i, c := range s.myCards {
+			if s.table.IsDead(c) {
+				return discard(i)
+			}
+		}
+	}
+
+	// Otherwise pass by giving a hint, to avoid running down the deck while
+	// someone else could play.
+	if blueTokens > 0 && (blueTokens == s.maxBlueTokens || s.someoneCanPlay()) {
+		if hint := s.anyHint(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+
+	if i := s.safestDiscard(); i >= 0 {
+		return discard(i)
+	}
+
+	if blueTokens > 0 {
+		if hint := s.anyHint(); hint != nil {
+			return yanhuo.Action{GiveInformation: hint}
+		}
+	}
+	return discard(0)
+}
+
+func (s *OracleStrategy) Rejoin(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int,
+	action yanhuo.Action) {
+	s.table.UpdateHands(otherPlayersCards)
+}
+
+func (s *OracleStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+	switch {
+	case action.Discard != nil:
+		s.table.Replace(actor, action.Discard.Index)
+	case action.Play != nil:
+		s.table.Replace(actor, action.Play.Index)
+	}
+}
+
+func (s *OracleStrategy) ObserveDiscard(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDiscard(p, c, i)
+}
+
+func (s *OracleStrategy) ObserveDraw(p yanhuo.PlayerIndex, c yanhuo.Card, i yanhuo.HandIndex) {
+	s.table.ObserveDraw(p, c, i)
+}
+
+func (s *OracleStrategy) ObservePlay(p yanhuo.PlayerIndex, c yanhuo.Card, successful bool) {
+	s.table.ObservePlay(p, c, successful)
+}
+
+// Prefers the lowest playable card, since it's most likely to unlock other
+// players' cards.
+func (s *OracleStrategy) bestPlay() int {
+	best := -1
+	for i, c := range s.myCards {
+		if s.table.IsPlayable(c) && (best == -1 || c.Value < s.myCards[best].Value) {
+			best = i
+		}
+	}
+	return best
+}
+
+func (s *OracleStrategy) someoneCanPlay() bool {
+	for _, cards := range s.table.Hands {
+		for _, c := range cards {
+			if s.table.IsPlayable(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the card we can best afford to lose: one with another copy in
+// somebody's hand, or else the one needed furthest in the future. Never
+// returns the last copy of a card that's still needed, if it can help it.
+func (s *OracleStrategy) safestDiscard() int {
+	for i, c := range s.myCards {
+		if s.table.IsDead(c) || s.copiesHeld(c) > 1 {
+			return i
+		}
+	}
+
+	best := -1
+	for i, c := range s.myCards {
+		if s.table.IsCritical(c) {
+			continue
+		}
+		if best == -1 || c.Value > s.myCards[best].Value {
+			best = i
+		}
+	}
+	return best
+}
+
+// Gives the next player any hint the rules allow, skipping players who
+// can't be given one (e.g. because their hands are empty). Returns nil if
+// nobody can.
+func (s *OracleStrategy) anyHint() *yanhuo.GiveInformationAction {
+	for n := 1; n < s.numPlayers; n++ {
+		target := yanhuo.PlayerIndex((int(s.me) + n) % s.numPlayers)
+		if hints := s.rules.LegalHints(target, s.table.Hands[target]); len(hints) > 0 {
+			return hints[0]
+		}
+	}
+	return nil
+}
+
+func (s *OracleStrategy) copiesHeld(c yanhuo.Card) int {
+	n := 0
+	for _, other := range s.myCards {
+		if other == c {
+			n++
+		}
+	}
+	for _, cards := range s.table.Hands {
+		for _, other := range cards {
+			if other == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func discard(i int) yanhuo.Action {
+	return yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: yanhuo.HandIndex(i)}}
+}
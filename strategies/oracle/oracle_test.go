@@ -0,0 +1,82 @@
+package oracle
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"testing"
+)
+
+func oracles(n int) []yanhuo.PlayerStrategy {
+	players := []yanhuo.PlayerStrategy{}
+	for i := 0; i < n; i++ {
+		players = append(players, NewOracleStrategy())
+	}
+	return players
+}
+
+func TestRequiresCheatingOption(t *testing.T) {
+	if _, err := yanhuo.InitializeGame(oracles(3), nil); err == nil {
+		t.Errorf("Oracles should not be allowed to play without AllowCheating")
+	}
+}
+
+func TestPlayFullGames(t *testing.T) {
+	total := 0
+	for seed := int64(1); seed <= 10; seed++ {
+		game, err := yanhuo.InitializeGameWithOptions(oracles(4), nil,
+			yanhuo.GameOptions{Seed: seed, AllowCheating: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+
+		record := game.Record()
+		if !record.Cheating {
+			t.Errorf("Game with seed %d should be flagged as cheating", seed)
+		}
+		total += record.Score
+	}
+
+	if total < 10*23 {
+		t.Errorf("Oracles should nearly always score 25, but averaged %f", float64(total)/10)
+	}
+}
+
+func TestPlayVariantGames(t *testing.T) {
+	rules := yanhuo.StandardRules()
+	rules.Suits = map[yanhuo.Color]yanhuo.SuitRules{
+		yanhuo.RED:   {ValueHints: yanhuo.TouchAlways}, // pink
+		yanhuo.GREEN: {ValueHints: yanhuo.TouchNever},  // brown
+	}
+
+	// the game panics if a hint touches the wrong cards
+	for seed := int64(1); seed <= 10; seed++ {
+		game, err := yanhuo.InitializeGameWithOptions(oracles(4), nil,
+			yanhuo.GameOptions{Seed: seed, AllowCheating: true, Rules: &rules})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game.Play()
+	}
+}
+
+func TestHintSkipsEmptyHands(t *testing.T) {
+	s := NewOracleStrategy()
+	others := map[yanhuo.PlayerIndex][]yanhuo.Card{
+		1: {},
+		2: {{Color: yanhuo.RED, Value: 3}},
+	}
+	s.StartGame(0, others, 1, 8, 3)
+	s.ObserveOwnCards([]yanhuo.Card{{Color: yanhuo.RED, Value: 5}})
+
+	action := s.Act(0, others, 1, 8, 3)
+	if action.GiveInformation == nil || action.GiveInformation.PlayerIndex != 2 {
+		t.Errorf("Expected a hint to player 2, whose hand isn't empty, got %+v", action)
+	}
+
+	others[2] = []yanhuo.Card{}
+	action = s.Act(0, others, 1, 8, 3)
+	if action.Discard == nil {
+		t.Errorf("Expected a discard with nobody else holding cards, got %+v", action)
+	}
+}
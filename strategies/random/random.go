@@ -0,0 +1,69 @@
+// Package random implements a strategy which picks uniformly among all the
+// legal moves. It's the floor any real strategy should clear.
+package random
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"math/rand"
+	"sort"
+)
+
+type RandomStrategy struct {
+	rand  *rand.Rand
+	rules yanhuo.Rules
+}
+
+// The same seed always produces the same choices, given the same game.
+func NewRandomStrategy(seed int64) *RandomStrategy {
+	return &RandomStrategy{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Learns the rules, so that only hints the rules allow are chosen.
+func (s *RandomStrategy) ObserveView(v *yanhuo.View) {
+	s.rules = v.Rules()
+}
+
+func (s *RandomStrategy) StartGame(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) {
+}
+
+func (s *RandomStrategy) Act(
+	myPlayerIndex yanhuo.PlayerIndex,
+	otherPlayersCards map[yanhuo.PlayerIndex][]yanhuo.Card,
+	myNumCards int,
+	blueTokens int,
+	redTokens int) yanhuo.Action {
+	moves := []yanhuo.Action{}
+
+	for i := 0; i < myNumCards; i++ {
+		moves = append(moves,
+			yanhuo.Action{Play: &yanhuo.PlayAction{Index: yanhuo.HandIndex(i)}},
+			yanhuo.Action{Discard: &yanhuo.DiscardAction{Index: yanhuo.HandIndex(i)}})
+	}
+
+	if blueTokens > 0 {
+		// visit players in a fixed order, so the seed fully determines
+		// our choice
+		players := []int{}
+		for p := range otherPlayersCards {
+			players = append(players, int(p))
+		}
+		sort.Ints(players)
+
+		for _, p := range players {
+			for _, hint := range s.rules.LegalHints(yanhuo.PlayerIndex(p), otherPlayersCards[yanhuo.PlayerIndex(p)]) {
+				moves = append(moves, yanhuo.Action{GiveInformation: hint})
+			}
+		}
+	}
+
+	return moves[s.rand.Intn(len(moves))]
+}
+
+func (s *RandomStrategy) ObserveAction(actor yanhuo.PlayerIndex, action yanhuo.Action) {
+}
@@ -0,0 +1,49 @@
+package random
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func play(seed int64, rules *yanhuo.Rules) *yanhuo.GameRecord {
+	players := []yanhuo.PlayerStrategy{}
+	for i := 0; i < 3; i++ {
+		players = append(players, NewRandomStrategy(seed+int64(i)))
+	}
+	game, err := yanhuo.InitializeGameWithOptions(players, nil, yanhuo.GameOptions{Seed: seed, Rules: rules})
+	if err != nil {
+		panic(err)
+	}
+	game.Play()
+	return game.Record()
+}
+
+func TestOnlyLegalMoves(t *testing.T) {
+	// the game panics if a player makes an illegal move
+	rules := yanhuo.StandardRules()
+	rules.Suits = map[yanhuo.Color]yanhuo.SuitRules{
+		yanhuo.RED:  {ColorHints: yanhuo.TouchAlways},
+		yanhuo.BLUE: {ValueHints: yanhuo.TouchNever},
+	}
+	for seed := int64(1); seed <= 20; seed++ {
+		play(seed, nil)
+		play(seed, &rules)
+	}
+}
+
+func TestSameSeedSameGame(t *testing.T) {
+	a, err := json.Marshal(play(7, nil).Turns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(play(7, nil).Turns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("The same seeds should make the same moves:\n%s\n%s", a, b)
+	}
+}
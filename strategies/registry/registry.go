@@ -0,0 +1,97 @@
+// Package registry creates strategies by name, so that tools can choose the
+// players for a game from the command line or a config file.
+package registry
+
+import (
+	"github.com/mrjones/yanhuo/core"
+	"github.com/mrjones/yanhuo/strategies/alwaysplay"
+	"github.com/mrjones/yanhuo/strategies/conventions"
+	"github.com/mrjones/yanhuo/strategies/hatguessing"
+	"github.com/mrjones/yanhuo/strategies/heuristic"
+	"github.com/mrjones/yanhuo/strategies/oracle"
+	"github.com/mrjones/yanhuo/strategies/random"
+
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Creates a new strategy, ready to play one game. Strategies which make
+// random choices must make the same ones given the same seed.
+type Factory func(seed int64) yanhuo.PlayerStrategy
+
+var (
+	mu        sync.Mutex
+	factories = map[string]Factory{
+		"alwaysplay": func(seed int64) yanhuo.PlayerStrategy {
+			return &alwaysplay.AlwaysPlayFirstCardStrategy{Name: fmt.Sprintf("alwaysplay-%d", seed)}
+		},
+		"conventions": func(seed int64) yanhuo.PlayerStrategy {
+			return conventions.NewConventionStrategy(fmt.Sprintf("conventions-%d", seed))
+		},
+		"hatguessing": func(seed int64) yanhuo.PlayerStrategy {
+			return hatguessing.NewHatGuessingStrategy()
+		},
+		"heuristic": func(seed int64) yanhuo.PlayerStrategy {
+			return heuristic.NewHeuristicStrategy(seed)
+		},
+		// Only usable in games with yanhuo.GameOptions.AllowCheating.
+		"oracle": func(seed int64) yanhuo.PlayerStrategy {
+			return oracle.NewOracleStrategy()
+		},
+		"random": func(seed int64) yanhuo.PlayerStrategy {
+			return random.NewRandomStrategy(seed)
+		},
+		"safediscard": func(seed int64) yanhuo.PlayerStrategy {
+			return heuristic.NewSafeDiscardStrategy(seed)
+		},
+	}
+)
+
+// Makes a strategy available under name, replacing any existing strategy
+// with that name.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// Creates a new instance of the strategy registered under name.
+func New(name string, seed int64) (yanhuo.PlayerStrategy, error) {
+	mu.Lock()
+	factory, ok := factories[name]
+	mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("Unknown strategy: %q", name)
+	}
+	return factory(seed), nil
+}
+
+// Creates one strategy for each name. Each gets its own seed, derived from
+// seed and its position, so that players using the same strategy don't make
+// identical choices.
+func NewPlayers(names []string, seed int64) ([]yanhuo.PlayerStrategy, error) {
+	players := make([]yanhuo.PlayerStrategy, len(names))
+	for i, name := range names {
+		player, err := New(name, seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		players[i] = player
+	}
+	return players, nil
+}
+
+// Returns the names of all registered strategies, sorted.
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := []string{}
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
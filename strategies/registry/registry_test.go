@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"github.com/mrjones/yanhuo/core"
+
+	"testing"
+)
+
+func playGame(t *testing.T, name string, seed int64) *yanhuo.GameRecord {
+	players, err := NewPlayers([]string{name, name, name}, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := yanhuo.InitializeGameWithOptions(players, nil,
+		yanhuo.GameOptions{Seed: seed, AllowCheating: name == "oracle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Play()
+	return game.Record()
+}
+
+func TestStrategiesAreDeterministic(t *testing.T) {
+	for _, name := range Names() {
+		if name == "alwaysplay" {
+			// too chatty, and trivially deterministic
+			continue
+		}
+
+		r1 := playGame(t, name, 7)
+		r2 := playGame(t, name, 7)
+
+		if len(r1.Turns) != len(r2.Turns) {
+			t.Errorf("%s: same seed played %d turns, then %d", name, len(r1.Turns), len(r2.Turns))
+			continue
+		}
+		for i := range r1.Turns {
+			if r1.Turns[i].Action.DebugString() != r2.Turns[i].Action.DebugString() {
+				t.Errorf("%s: same seed diverged at turn %d: %s vs %s", name, i,
+					r1.Turns[i].Action.DebugString(), r2.Turns[i].Action.DebugString())
+				break
+			}
+		}
+	}
+}
+
+func TestUnknownStrategy(t *testing.T) {
+	if _, err := New("no-such-strategy", 0); err == nil {
+		t.Errorf("Expected an error for an unknown strategy")
+	}
+}